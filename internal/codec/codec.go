@@ -0,0 +1,225 @@
+// Package codec 提供 maps 与 set 共用的序列化辅助函数，保证 JSON 输出顺序稳定
+package codec
+
+import (
+	"bytes"
+	"cmp"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+var (
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// pair key 不能作为 JSON 对象的 key 时，以 [{"key":k,"value":v}] 的形式编码
+type pair[K comparable, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// Sort 对元素排序：整数、浮点数、字符串按值排序，其余类型按 JSON 编码后的字节排序，
+// 从而保证任意可比较类型的输出顺序都是确定的。排序依据在排序前按元素类型确定一次，
+// 内置类型直接排序，底层为这些类型的自定义类型与其他类型则先为每个元素计算一次排序键
+func Sort[T comparable](items []T) error {
+	if len(items) < 2 {
+		return nil
+	}
+	switch s := any(items).(type) {
+	case []string:
+		slices.Sort(s)
+	case []int:
+		slices.Sort(s)
+	case []int64:
+		slices.Sort(s)
+	case []int32:
+		slices.Sort(s)
+	case []uint:
+		slices.Sort(s)
+	case []uint64:
+		slices.Sort(s)
+	case []uint32:
+		slices.Sort(s)
+	case []float64:
+		slices.Sort(s)
+	default:
+		return sortByKind(items)
+	}
+	return nil
+}
+
+func sortByKind[T comparable](items []T) error {
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		sortByKey(items, func(v reflect.Value) int64 { return v.Int() })
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		sortByKey(items, func(v reflect.Value) uint64 { return v.Uint() })
+	case reflect.Float32, reflect.Float64:
+		sortByKey(items, func(v reflect.Value) float64 { return v.Float() })
+	case reflect.String:
+		sortByKey(items, func(v reflect.Value) string { return v.String() })
+	default:
+		return sortByJSON(items)
+	}
+	return nil
+}
+
+// keyed 元素及其排序键
+type keyed[T any, S any] struct {
+	item T
+	key  S
+}
+
+// sortByKey 为每个元素计算一次排序键后排序
+func sortByKey[T any, S cmp.Ordered](items []T, key func(reflect.Value) S) {
+	list := make([]keyed[T, S], len(items))
+	for i, item := range items {
+		list[i] = keyed[T, S]{item: item, key: key(reflect.ValueOf(item))}
+	}
+	slices.SortFunc(list, func(a, b keyed[T, S]) int {
+		return cmp.Compare(a.key, b.key)
+	})
+	for i := range list {
+		items[i] = list[i].item
+	}
+}
+
+func sortByJSON[T comparable](items []T) error {
+	list := make([]keyed[T, []byte], len(items))
+	for i, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		list[i] = keyed[T, []byte]{item: item, key: data}
+	}
+	slices.SortFunc(list, func(a, b keyed[T, []byte]) int {
+		return bytes.Compare(a.key, b.key)
+	})
+	for i := range list {
+		items[i] = list[i].item
+	}
+	return nil
+}
+
+// MarshalMap 将 map 编码为 key 有序的 JSON。
+// key 为字符串、整数或实现了 encoding.TextMarshaler/TextUnmarshaler 时编码为 JSON 对象，
+// 否则编码为 [{"key":k,"value":v}] 形式的数组
+func MarshalMap[K comparable, V any](items map[K]V) ([]byte, error) {
+	keys := make([]K, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	if err := Sort(keys); err != nil {
+		return nil, err
+	}
+
+	if !isObjectKey[K]() {
+		pairs := make([]pair[K, V], len(keys))
+		for i, k := range keys {
+			pairs[i] = pair[K, V]{Key: k, Value: items[k]}
+		}
+		return json.Marshal(pairs)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := keyName(reflect.ValueOf(k))
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+		buf.WriteByte(':')
+		if data, err = json.Marshal(items[k]); err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalMap 解码 MarshalMap 的输出，同时支持 JSON 对象与键值对数组两种形式
+func UnmarshalMap[K comparable, V any](data []byte) (map[K]V, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("codec: unexpected end of JSON input")
+	}
+	switch data[0] {
+	case '[':
+		var pairs []pair[K, V]
+		if err := json.Unmarshal(data, &pairs); err != nil {
+			return nil, err
+		}
+		items := make(map[K]V, len(pairs))
+		for _, p := range pairs {
+			items[p.Key] = p.Value
+		}
+		return items, nil
+	default:
+		var items map[K]V
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+}
+
+// MarshalSlice 将元素排序后编码为 JSON 数组，会修改 items 的顺序
+func MarshalSlice[T comparable](items []T) ([]byte, error) {
+	if err := Sort(items); err != nil {
+		return nil, err
+	}
+	return MarshalOrdered(items)
+}
+
+// MarshalOrdered 按 items 原有的顺序编码为 JSON 数组，用于本身有序的容器，nil 编码为 []
+func MarshalOrdered[T any](items []T) ([]byte, error) {
+	if items == nil {
+		items = []T{}
+	}
+	return json.Marshal(items)
+}
+
+// isObjectKey K 是否可以作为 JSON 对象的 key 并被 encoding/json 解码
+func isObjectKey[K comparable]() bool {
+	t := reflect.TypeFor[K]()
+	switch t.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return t.Implements(textMarshalerType) && reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// keyName 与 encoding/json 对 map key 的处理方式一致
+func keyName(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", errors.New("codec: unsupported map key type " + k.Type().String())
+}
@@ -0,0 +1,163 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func TestMarshalMap(t *testing.T) {
+	testCase := []struct {
+		name  string
+		input any
+		want  string
+	}{
+		{
+			name:  "string key",
+			input: map[string]int{"b": 2, "a": 1, "c": 3},
+			want:  `{"a":1,"b":2,"c":3}`,
+		},
+		{
+			name:  "int key sorted by value",
+			input: map[int]string{10: "x", 9: "y", -1: "z"},
+			want:  `{"-1":"z","9":"y","10":"x"}`,
+		},
+		{
+			name:  "float key as pairs",
+			input: map[float64]int{2.5: 1, -1: 2},
+			want:  `[{"key":-1,"value":2},{"key":2.5,"value":1}]`,
+		},
+		{
+			name:  "struct key as pairs",
+			input: map[point]int{{X: 2, Y: 1}: 1, {X: 1, Y: 2}: 2},
+			want:  `[{"key":{"x":1,"y":2},"value":2},{"key":{"x":2,"y":1},"value":1}]`,
+		},
+		{
+			name:  "empty map",
+			input: map[string]int{},
+			want:  `{}`,
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				data []byte
+				err  error
+			)
+			switch m := tc.input.(type) {
+			case map[string]int:
+				data, err = MarshalMap(m)
+			case map[int]string:
+				data, err = MarshalMap(m)
+			case map[float64]int:
+				data, err = MarshalMap(m)
+			case map[point]int:
+				data, err = MarshalMap(m)
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, string(data))
+		})
+	}
+}
+
+func TestUnmarshalMap(t *testing.T) {
+	items, err := UnmarshalMap[int, string]([]byte(`{"-1":"z","10":"x"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]string{-1: "z", 10: "x"}, items)
+
+	pairs, err := UnmarshalMap[point, int]([]byte(`[{"key":{"x":1,"y":2},"value":2}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[point]int{{X: 1, Y: 2}: 2}, pairs)
+
+	_, err = UnmarshalMap[string, int]([]byte(` `))
+	assert.Error(t, err)
+	_, err = UnmarshalMap[string, int]([]byte(`{"a":"b"}`))
+	assert.Error(t, err)
+}
+
+func TestMarshalSlice(t *testing.T) {
+	data, err := MarshalSlice([]int{3, 10, -2, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, `[-2,1,3,10]`, string(data))
+
+	data, err = MarshalSlice([]string(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, string(data))
+}
+
+type level int8
+
+type name string
+
+func TestSort(t *testing.T) {
+	testCase := []struct {
+		name string
+		sort func() (any, error)
+		want any
+	}{
+		{
+			name: "builtin",
+			sort: func() (any, error) {
+				items := []int{3, 10, -2, 1}
+				return items, Sort(items)
+			},
+			want: []int{-2, 1, 3, 10},
+		},
+		{
+			name: "named int",
+			sort: func() (any, error) {
+				items := []level{3, -1, 2}
+				return items, Sort(items)
+			},
+			want: []level{-1, 2, 3},
+		},
+		{
+			name: "named string",
+			sort: func() (any, error) {
+				items := []name{"b", "c", "a"}
+				return items, Sort(items)
+			},
+			want: []name{"a", "b", "c"},
+		},
+		{
+			name: "float32",
+			sort: func() (any, error) {
+				items := []float32{1.5, -2, 0}
+				return items, Sort(items)
+			},
+			want: []float32{-2, 0, 1.5},
+		},
+		{
+			name: "json",
+			sort: func() (any, error) {
+				items := []point{{X: 2}, {X: 1, Y: 3}, {X: 1}}
+				return items, Sort(items)
+			},
+			want: []point{{X: 1}, {X: 1, Y: 3}, {X: 2}},
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.sort()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestMarshalOrdered(t *testing.T) {
+	data, err := MarshalOrdered([]int{3, 10, -2, 1})
+	assert.NoError(t, err)
+	assert.Equal(t, `[3,10,-2,1]`, string(data))
+
+	data, err = MarshalOrdered([][]int(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, string(data))
+}
@@ -0,0 +1,106 @@
+// Package hashing 提供 maps 与 sketch 共用的快速哈希函数
+package hashing
+
+import (
+	"hash/maphash"
+	"math/rand/v2"
+)
+
+var (
+	// seed 进程内随机的种子，Hash 的字符串与其他类型使用 maphash，StableHash 中没有特化的类型也使用它
+	seed = maphash.MakeSeed()
+	// intSeed 进程内随机的种子，Hash 的整数与它异或后再混合
+	intSeed = rand.Uint64()
+)
+
+// Hash 带进程内随机种子的哈希函数，用于哈希表，外部无法构造大量冲突的 key（HashDoS）。
+// 字符串使用 maphash.String，整数与种子异或后混合，其余可比较类型使用 maphash.Comparable，
+// 不会经过 fmt 产生额外的内存分配。哈希值只在同一进程内一致
+func Hash[K comparable](k K) uint64 {
+	switch x := any(k).(type) {
+	case string:
+		return maphash.String(seed, x)
+	case int:
+		return Mix64(uint64(x) ^ intSeed)
+	case int8:
+		return Mix64(uint64(x) ^ intSeed)
+	case int16:
+		return Mix64(uint64(x) ^ intSeed)
+	case int32:
+		return Mix64(uint64(x) ^ intSeed)
+	case int64:
+		return Mix64(uint64(x) ^ intSeed)
+	case uint:
+		return Mix64(uint64(x) ^ intSeed)
+	case uint8:
+		return Mix64(uint64(x) ^ intSeed)
+	case uint16:
+		return Mix64(uint64(x) ^ intSeed)
+	case uint32:
+		return Mix64(uint64(x) ^ intSeed)
+	case uint64:
+		return Mix64(x ^ intSeed)
+	case uintptr:
+		return Mix64(uint64(x) ^ intSeed)
+	default:
+		return maphash.Comparable(seed, k)
+	}
+}
+
+// StableHash 固定种子的哈希函数，字符串和整数的哈希值在不同进程间保持一致，供需要序列化的 sketch 使用；
+// 其余类型退化为带进程内种子的 maphash.Comparable，只在同一进程内一致。
+// 哈希值可以被预测，不要用于以外部输入为 key 的哈希表
+func StableHash[K comparable](k K) uint64 {
+	switch x := any(k).(type) {
+	case string:
+		// FNV-1a inline (no allocation)
+		var h uint64 = 1469598103934665603
+		for i := 0; i < len(x); i++ {
+			h ^= uint64(x[i])
+			h *= 1099511628211
+		}
+		return h
+	case int:
+		return Mix64(uint64(x))
+	case int8:
+		return Mix64(uint64(x))
+	case int16:
+		return Mix64(uint64(x))
+	case int32:
+		return Mix64(uint64(x))
+	case int64:
+		return Mix64(uint64(x))
+	case uint:
+		return Mix64(uint64(x))
+	case uint8:
+		return Mix64(uint64(x))
+	case uint16:
+		return Mix64(uint64(x))
+	case uint32:
+		return Mix64(uint64(x))
+	case uint64:
+		return Mix64(x)
+	case uintptr:
+		return Mix64(uint64(x))
+	default:
+		return maphash.Comparable(seed, k)
+	}
+}
+
+// Stable 类型 K 的 StableHash 是否在不同进程间保持一致
+func Stable[K comparable]() bool {
+	var k K
+	switch any(k).(type) {
+	case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+// Mix64 splitmix64 的最终混合步骤，使相邻整数的哈希值充分离散
+func Mix64(u uint64) uint64 {
+	u = (u ^ (u >> 30)) * 0xbf58476d1ce4e5b9
+	u = (u ^ (u >> 27)) * 0x94d049bb133111eb
+	return u ^ (u >> 31)
+}
@@ -0,0 +1,85 @@
+package hashing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStableHash(t *testing.T) {
+	testCase := []struct {
+		name string
+		hash uint64
+		want uint64
+	}{
+		{
+			// 字符串的哈希值需要跨进程稳定，sketch 的序列化结果依赖于此
+			name: "empty string",
+			hash: StableHash(""),
+			want: 0x14650fb0739d0383,
+		},
+		{
+			name: "string",
+			hash: StableHash("a"),
+			want: 0x44bd8ad473cd9906,
+		},
+		{
+			name: "integer",
+			hash: StableHash(uint64(42)),
+			want: Mix64(42),
+		},
+		{
+			name: "integer kinds share mixing",
+			hash: StableHash(int32(42)),
+			want: StableHash(uint64(42)),
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.hash)
+		})
+	}
+}
+
+func TestHashSeeded(t *testing.T) {
+	// 同一进程内一致
+	assert.Equal(t, Hash("gokit"), Hash("gokit"))
+	assert.Equal(t, Hash(int32(42)), Hash(uint64(42)))
+	// 带随机种子，与固定种子的结果不同
+	assert.NotEqual(t, StableHash("gokit"), Hash("gokit"))
+	assert.NotEqual(t, StableHash(42), Hash(42))
+	assert.NotEqual(t, Hash("a"), Hash("b"))
+}
+
+func TestHashStructKey(t *testing.T) {
+	type point struct {
+		X, Y int
+		Name string
+	}
+	a := point{X: 1, Y: 2, Name: "a"}
+	b := point{X: 1, Y: 2, Name: "a"}
+	c := point{X: 2, Y: 1, Name: "a"}
+
+	assert.Equal(t, Hash(a), Hash(b))
+	assert.NotEqual(t, Hash(a), Hash(c))
+}
+
+func TestStable(t *testing.T) {
+	assert.True(t, Stable[string]())
+	assert.True(t, Stable[uint16]())
+	assert.False(t, Stable[float64]())
+	assert.False(t, Stable[struct{ X int }]())
+}
+
+func BenchmarkHashStruct(b *testing.B) {
+	type point struct {
+		X, Y int
+		Name string
+	}
+	p := point{X: 1, Y: 2, Name: "gokit"}
+	b.ReportAllocs()
+	for b.Loop() {
+		Hash(p)
+	}
+}
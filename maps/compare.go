@@ -0,0 +1,23 @@
+package maps
+
+// CompareAndSwapper 支持自定义比较的 CAS 操作，Map 的所有实现都支持。
+// V 可比较时使用包级的 CompareAndSwap/CompareAndDelete 即可
+type CompareAndSwapper[K comparable, V any] interface {
+	CompareAndSwapFunc(key K, old, new V, eq func(a, b V) bool) (swapped bool)
+	CompareAndDeleteFunc(key K, old V, eq func(a, b V) bool) (deleted bool)
+}
+
+// CompareAndSwap 当前值等于 old 时替换为 new，V 必须可比较，不可比较的类型在编译期报错，
+// 此时可以使用 m.CompareAndSwapFunc 自定义比较
+func CompareAndSwap[K comparable, V comparable](m CompareAndSwapper[K, V], key K, old, new V) (swapped bool) {
+	return m.CompareAndSwapFunc(key, old, new, equal[V])
+}
+
+// CompareAndDelete 当前值等于 old 时删除 key，V 必须可比较
+func CompareAndDelete[K comparable, V comparable](m CompareAndSwapper[K, V], key K, old V) (deleted bool) {
+	return m.CompareAndDeleteFunc(key, old, equal[V])
+}
+
+func equal[V comparable](a, b V) bool {
+	return a == b
+}
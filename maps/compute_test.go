@@ -0,0 +1,83 @@
+package maps
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// concurrentMaps 并发安全的 Map 实现
+func concurrentMaps() map[string]func() Map[string, int] {
+	return map[string]func() Map[string, int]{
+		"ShardMap":      func() Map[string, int] { return NewShardMap[string, int](WithShardCount(4)) },
+		"ConcurrentMap": func() Map[string, int] { return NewConcurrentMap[string, int]() },
+		"RCUMap":        func() Map[string, int] { return NewRCUMap[string, int]() },
+	}
+}
+
+func TestComputeConcurrent(t *testing.T) {
+	for name, newMap := range concurrentMaps() {
+		t.Run(name, func(t *testing.T) {
+			m := newMap()
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 1000; j++ {
+						m.Compute("counter", func(old int, ok bool) (int, bool) {
+							return old + 1, true
+						})
+					}
+				}()
+			}
+			wg.Wait()
+
+			val, _ := m.Get("counter")
+			assert.Equal(t, 8000, val)
+		})
+	}
+}
+
+func TestShardMapComputeTTL(t *testing.T) {
+	m := NewShardMap[string, int](WithShardCount(4))
+	m.SetWithTTL("a", 1, 10*time.Millisecond)
+	assert.True(t, CompareAndSwap(m, "a", 1, 2))
+	time.Sleep(30 * time.Millisecond)
+
+	// 已过期的 key 视为不存在，且计数同步减少
+	val, loaded := m.GetOrSet("a", 3)
+	assert.False(t, loaded)
+	assert.Equal(t, 3, val)
+	assert.Equal(t, 1, m.Len())
+
+	_, ok := m.LoadAndDelete("a")
+	assert.True(t, ok)
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestCompareAndSwapFunc(t *testing.T) {
+	// V 不可比较时通过 eq 自定义比较，CompareAndSwap 对这类 V 无法通过编译
+	testCase := map[string]Map[string, []int]{
+		"HashMap":       NewHashMap[string, []int](),
+		"ConcurrentMap": NewConcurrentMap[string, []int](),
+		"ShardMap":      NewShardMap[string, []int](),
+		"RCUMap":        NewRCUMap[string, []int](),
+	}
+	for name, m := range testCase {
+		t.Run(name, func(t *testing.T) {
+			m.Set("a", []int{1})
+			assert.False(t, m.CompareAndSwapFunc("a", []int{2}, []int{3}, slices.Equal[[]int]))
+			assert.True(t, m.CompareAndSwapFunc("a", []int{1}, []int{2}, slices.Equal[[]int]))
+			val, _ := m.Get("a")
+			assert.Equal(t, []int{2}, val)
+
+			assert.False(t, m.CompareAndDeleteFunc("a", []int{1}, slices.Equal[[]int]))
+			assert.True(t, m.CompareAndDeleteFunc("a", []int{2}, slices.Equal[[]int]))
+			assert.Equal(t, 0, m.Len())
+		})
+	}
+}
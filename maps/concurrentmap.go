@@ -1,6 +1,9 @@
 package maps
 
-import "sync"
+import (
+	"iter"
+	"sync"
+)
 
 // 基于sync.RWMutex 实现的一个并发安全的map
 
@@ -62,3 +65,137 @@ func (m *ConcurrentMap[K, V]) Values() []V {
 	}
 	return values
 }
+
+// Range 遍历所有键值, f返回true则停止。通过 snapshot（复制）实现，
+// 回调中可以安全调用 Set/Delete（但不保证写入能被当前 Range 看到）
+func (m *ConcurrentMap[K, V]) Range(f func(key K, value V) (stop bool)) {
+	for k, v := range m.All() {
+		if f(k, v) {
+			return
+		}
+	}
+}
+
+// All 返回遍历所有键值的迭代器。ConcurrentMap 只有一把锁，因此会先复制再遍历，
+// 循环体中可以安全调用 Set/Delete（但不保证写入能被当前遍历看到）
+func (m *ConcurrentMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		type pair struct {
+			k K
+			v V
+		}
+		m.mux.RLock()
+		items := make([]pair, 0, len(m.container))
+		for k, v := range m.container {
+			items = append(items, pair{k, v})
+		}
+		m.mux.RUnlock()
+
+		for _, it := range items {
+			if !yield(it.k, it.v) {
+				return
+			}
+		}
+	}
+}
+
+// KeysSeq 返回遍历所有 key 的迭代器，见 All
+func (m *ConcurrentMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesSeq 返回遍历所有 value 的迭代器，见 All
+func (m *ConcurrentMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// GetOrSet key 存在时返回已有的值，loaded 为 true；否则写入 val 并返回 val，loaded 为 false
+func (m *ConcurrentMap[K, V]) GetOrSet(key K, val V) (actual V, loaded bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if old, ok := m.container[key]; ok {
+		return old, true
+	}
+	m.container[key] = val
+	return val, false
+}
+
+// LoadAndDelete 删除 key 并返回删除前的值
+func (m *ConcurrentMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	val, ok := m.container[key]
+	if ok {
+		delete(m.container, key)
+	}
+	return val, ok
+}
+
+// CompareAndSwapFunc 当前值与 old 按 eq 比较相等时替换为 new，eq 在锁内执行
+func (m *ConcurrentMap[K, V]) CompareAndSwapFunc(key K, old, new V, eq func(a, b V) bool) (swapped bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	cur, ok := m.container[key]
+	if !ok || !eq(cur, old) {
+		return false
+	}
+	m.container[key] = new
+	return true
+}
+
+// CompareAndDeleteFunc 当前值与 old 按 eq 比较相等时删除 key，eq 在锁内执行
+func (m *ConcurrentMap[K, V]) CompareAndDeleteFunc(key K, old V, eq func(a, b V) bool) (deleted bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	cur, ok := m.container[key]
+	if !ok || !eq(cur, old) {
+		return false
+	}
+	delete(m.container, key)
+	return true
+}
+
+// Compute 在锁内根据旧值计算新值：fn 返回 keep 为 false 时删除 key，否则写入 newVal。
+// 返回计算后的值以及 key 是否存在。fn 在锁内执行，不能再调用当前 map 的方法
+func (m *ConcurrentMap[K, V]) Compute(key K, fn func(old V, ok bool) (newVal V, keep bool)) (V, bool) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	old, ok := m.container[key]
+	newVal, keep := fn(old, ok)
+	if !keep {
+		delete(m.container, key)
+		var zero V
+		return zero, false
+	}
+	m.container[key] = newVal
+	return newVal, true
+}
+
+// Upsert 插入或更新：fn 根据 key 是否存在、旧值和传入的 val 计算最终写入的值并返回。
+// fn 在锁内执行，不能再调用当前 map 的方法
+func (m *ConcurrentMap[K, V]) Upsert(key K, val V, fn func(exist bool, old V, new V) V) V {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	old, ok := m.container[key]
+	res := fn(ok, old, val)
+	m.container[key] = res
+	return res
+}
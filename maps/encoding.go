@@ -0,0 +1,250 @@
+package maps
+
+import (
+	"bytes"
+	"encoding/gob"
+	"time"
+
+	"github.com/Ri0nGo/gokit/internal/codec"
+)
+
+// ShardMap、ConcurrentMap、RCUMap 的序列化。
+// JSON 输出按 key 排序，结果是确定的；gob 与 binary 使用同一种编码。
+// 解码时与 encoding/json 解码到 map 的行为一致：已有的数据会保留，同名 key 被覆盖。
+// 可以直接解码到零值的 ShardMap/ConcurrentMap/RCUMap 中
+
+// shardMapGob ShardMap 与 RCUMap 的 gob 编码格式，Expires 保存设置了 TTL 的 key 的过期时间（UnixNano）
+type shardMapGob[K comparable, V any] struct {
+	Items   map[K]V
+	Expires map[K]int64
+}
+
+// MarshalJSON 编码为 key 有序的 JSON 对象（不包含过期时间），已过期的 key 会被忽略
+func (s *ShardMap[K, V]) MarshalJSON() ([]byte, error) {
+	items := make(map[K]V, s.Len())
+	for k, v := range s.All() {
+		items[k] = v
+	}
+	return codec.MarshalMap(items)
+}
+
+// UnmarshalJSON 解码 MarshalJSON 的输出
+func (s *ShardMap[K, V]) UnmarshalJSON(data []byte) error {
+	items, err := codec.UnmarshalMap[K, V](data)
+	if err != nil {
+		return err
+	}
+	s.lazyInit()
+	for k, v := range items {
+		s.Set(k, v)
+	}
+	return nil
+}
+
+// GobEncode 使用 gob 编码，会保留各 key 的过期时间
+func (s *ShardMap[K, V]) GobEncode() ([]byte, error) {
+	payload := shardMapGob[K, V]{
+		Items:   make(map[K]V, s.Len()),
+		Expires: make(map[K]int64),
+	}
+	for e := range s.entries() {
+		payload.Items[e.key] = e.val
+		if e.expireAt > 0 {
+			payload.Expires[e.key] = e.expireAt
+		}
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode 解码 GobEncode 的输出，编码后已经过期的 key 会被丢弃
+func (s *ShardMap[K, V]) GobDecode(data []byte) error {
+	var payload shardMapGob[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&payload); err != nil {
+		return err
+	}
+	s.lazyInit()
+	now := time.Now().UnixNano()
+	for k, v := range payload.Items {
+		expireAt := payload.Expires[k]
+		if expireAt > 0 && now >= expireAt {
+			continue
+		}
+		sh := s.lockShard(k)
+		evicted, ok := s.setLocked(sh, k, v, expireAt)
+		sh.mu.Unlock()
+		s.notifyEvict(evicted, ok)
+	}
+	return nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler，与 GobEncode 相同
+func (s *ShardMap[K, V]) MarshalBinary() ([]byte, error) {
+	return s.GobEncode()
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler，与 GobDecode 相同
+func (s *ShardMap[K, V]) UnmarshalBinary(data []byte) error {
+	return s.GobDecode(data)
+}
+
+// lazyInit 使零值的 ShardMap 可以作为解码目标，按默认配置初始化。不是并发安全的
+func (s *ShardMap[K, V]) lazyInit() {
+	if s.table.Load() != nil {
+		return
+	}
+	if s.hasher == nil {
+		s.hasher = defaultHasher[K]
+	}
+	s.table.Store(s.newTable(defaultShardCnt, 0))
+}
+
+// MarshalJSON 编码为 key 有序的 JSON 对象
+func (m *ConcurrentMap[K, V]) MarshalJSON() ([]byte, error) {
+	return codec.MarshalMap(m.clone())
+}
+
+// UnmarshalJSON 解码 MarshalJSON 的输出
+func (m *ConcurrentMap[K, V]) UnmarshalJSON(data []byte) error {
+	items, err := codec.UnmarshalMap[K, V](data)
+	if err != nil {
+		return err
+	}
+	m.merge(items)
+	return nil
+}
+
+// GobEncode 使用 gob 编码
+func (m *ConcurrentMap[K, V]) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m.clone()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode 解码 GobEncode 的输出
+func (m *ConcurrentMap[K, V]) GobDecode(data []byte) error {
+	var items map[K]V
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&items); err != nil {
+		return err
+	}
+	m.merge(items)
+	return nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler，与 GobEncode 相同
+func (m *ConcurrentMap[K, V]) MarshalBinary() ([]byte, error) {
+	return m.GobEncode()
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler，与 GobDecode 相同
+func (m *ConcurrentMap[K, V]) UnmarshalBinary(data []byte) error {
+	return m.GobDecode(data)
+}
+
+// clone 在读锁内复制所有键值对，避免编码时长时间持有锁
+func (m *ConcurrentMap[K, V]) clone() map[K]V {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	items := make(map[K]V, len(m.container))
+	for k, v := range m.container {
+		items[k] = v
+	}
+	return items
+}
+
+// merge 写入解码得到的键值对，零值的 ConcurrentMap 会先初始化
+func (m *ConcurrentMap[K, V]) merge(items map[K]V) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if m.container == nil {
+		m.container = make(map[K]V, len(items))
+	}
+	for k, v := range items {
+		m.container[k] = v
+	}
+}
+
+// MarshalJSON 编码为 key 有序的 JSON 对象（不包含过期时间），已过期的 key 会被忽略
+func (m *RCUMap[K, V]) MarshalJSON() ([]byte, error) {
+	items := make(map[K]V, m.Len())
+	for e := range m.entries() {
+		items[e.key] = e.val
+	}
+	return codec.MarshalMap(items)
+}
+
+// UnmarshalJSON 解码 MarshalJSON 的输出
+func (m *RCUMap[K, V]) UnmarshalJSON(data []byte) error {
+	items, err := codec.UnmarshalMap[K, V](data)
+	if err != nil {
+		return err
+	}
+	m.lazyInit()
+	m.SetMany(items)
+	return nil
+}
+
+// GobEncode 使用 gob 编码，会保留各 key 的过期时间，格式与 ShardMap 相同
+func (m *RCUMap[K, V]) GobEncode() ([]byte, error) {
+	payload := shardMapGob[K, V]{
+		Items:   make(map[K]V, m.Len()),
+		Expires: make(map[K]int64),
+	}
+	for e := range m.entries() {
+		payload.Items[e.key] = e.val
+		if e.expireAt > 0 {
+			payload.Expires[e.key] = e.expireAt
+		}
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode 解码 GobEncode 的输出，编码后已经过期的 key 会被丢弃
+func (m *RCUMap[K, V]) GobDecode(data []byte) error {
+	var payload shardMapGob[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&payload); err != nil {
+		return err
+	}
+	m.lazyInit()
+	now := time.Now().UnixNano()
+	for k, v := range payload.Items {
+		expireAt := payload.Expires[k]
+		if expireAt > 0 && now >= expireAt {
+			continue
+		}
+		m.set(k, v, expireAt)
+	}
+	return nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler，与 GobEncode 相同
+func (m *RCUMap[K, V]) MarshalBinary() ([]byte, error) {
+	return m.GobEncode()
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler，与 GobDecode 相同
+func (m *RCUMap[K, V]) UnmarshalBinary(data []byte) error {
+	return m.GobDecode(data)
+}
+
+// lazyInit 使零值的 RCUMap 可以作为解码目标，按默认配置初始化。不是并发安全的
+func (m *RCUMap[K, V]) lazyInit() {
+	if m.table.Load() != nil {
+		return
+	}
+	if m.hasher == nil {
+		m.hasher = defaultHasher[K]
+	}
+	m.table.Store(newRCUTable[K, V](defaultRCUBuckets))
+}
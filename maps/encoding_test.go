@@ -0,0 +1,117 @@
+package maps
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardMapJSON(t *testing.T) {
+	m := NewShardMap[int, string](WithShardCount(4))
+	for i, v := range []string{"a", "b", "c", "d"} {
+		m.Set(i*5, v)
+	}
+	m.SetWithTTL(100, "expired", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	data, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"0":"a","5":"b","10":"c","15":"d"}`, string(data))
+
+	// 可以直接解码到零值的 ShardMap 中
+	var restored ShardMap[int, string]
+	assert.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, 4, restored.Len())
+	val, ok := restored.Get(10)
+	assert.True(t, ok)
+	assert.Equal(t, "c", val)
+}
+
+func TestShardMapGob(t *testing.T) {
+	m := NewShardMap[string, int](WithShardCount(4))
+	m.Set("a", 1)
+	m.SetWithTTL("b", 2, time.Hour)
+	m.SetWithTTL("c", 3, 20*time.Millisecond)
+
+	var buf bytes.Buffer
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(m))
+
+	restored := NewShardMap[string, int](WithShardCount(8))
+	assert.NoError(t, gob.NewDecoder(&buf).Decode(restored))
+	assert.Equal(t, 3, restored.Len())
+
+	_, ttl, ok := restored.GetWithTTL("b")
+	assert.True(t, ok)
+	assert.True(t, ttl > 59*time.Minute)
+	_, ttl, _ = restored.GetWithTTL("a")
+	assert.Equal(t, time.Duration(0), ttl)
+
+	// 过期时间在解码后仍然生效
+	time.Sleep(30 * time.Millisecond)
+	_, ok = restored.Get("c")
+	assert.False(t, ok)
+}
+
+func TestShardMapZeroValueEncode(t *testing.T) {
+	var m ShardMap[string, int]
+	data, err := json.Marshal(&m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, string(data))
+	var restored ShardMap[string, int]
+	assert.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, 0, restored.Len())
+
+	var buf bytes.Buffer
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(&m))
+	var decoded ShardMap[string, int]
+	assert.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+	assert.Equal(t, 0, decoded.Len())
+
+	data, err = m.MarshalBinary()
+	assert.NoError(t, err)
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	decoded.Set("a", 1)
+	assert.Equal(t, 1, decoded.Len())
+}
+
+func TestShardMapBinary(t *testing.T) {
+	m := NewShardMap[string, int]()
+	m.Set("a", 1)
+
+	data, err := m.MarshalBinary()
+	assert.NoError(t, err)
+
+	var restored ShardMap[string, int]
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	val, ok := restored.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+
+	assert.Error(t, restored.UnmarshalBinary([]byte("bad data")))
+}
+
+func TestConcurrentMapEncoding(t *testing.T) {
+	m := NewConcurrentMap[string, int]()
+	m.Set("b", 2)
+	m.Set("a", 1)
+
+	data, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1,"b":2}`, string(data))
+
+	var fromJSON ConcurrentMap[string, int]
+	assert.NoError(t, json.Unmarshal(data, &fromJSON))
+	assert.Equal(t, 2, fromJSON.Len())
+
+	bin, err := m.MarshalBinary()
+	assert.NoError(t, err)
+	var fromBinary ConcurrentMap[string, int]
+	assert.NoError(t, fromBinary.UnmarshalBinary(bin))
+	val, ok := fromBinary.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 2, val)
+}
@@ -0,0 +1,204 @@
+package maps
+
+import (
+	"container/list"
+	"iter"
+	"slices"
+)
+
+// EvictPolicy 淘汰策略，每个分片持有一个独立的实例。
+// 所有方法都在分片写锁内调用，实现无需自行加锁
+type EvictPolicy[K comparable] interface {
+	// Add 新增 key
+	Add(key K)
+	// Access 访问已存在的 key（Get 或覆盖写）
+	Access(key K)
+	// Remove 删除 key
+	Remove(key K)
+	// Victim 选出下一个被淘汰的 key，但不删除它
+	Victim() (K, bool)
+}
+
+// orderedPolicy 能够按淘汰顺序列出 key 的策略，持久化的快照按该顺序保存，
+// 恢复时依次写入即可重建相同的淘汰顺序
+type orderedPolicy[K comparable] interface {
+	// victims 从下一个被淘汰的 key 开始依次返回所有 key
+	victims() iter.Seq[K]
+}
+
+// ---------------- LRU ---------------- //
+
+// lruPolicy 最近最少使用，链表头部为最近访问的 key
+type lruPolicy[K comparable] struct {
+	ll    *list.List
+	items map[K]*list.Element
+	// touch 为 false 时访问不调整顺序，即 FIFO
+	touch bool
+}
+
+// NewLRUPolicy 淘汰最久未被访问的 key
+func NewLRUPolicy[K comparable]() EvictPolicy[K] {
+	return &lruPolicy[K]{
+		ll:    list.New(),
+		items: make(map[K]*list.Element),
+		touch: true,
+	}
+}
+
+// NewFIFOPolicy 淘汰最早写入的 key，访问不影响淘汰顺序
+func NewFIFOPolicy[K comparable]() EvictPolicy[K] {
+	return &lruPolicy[K]{
+		ll:    list.New(),
+		items: make(map[K]*list.Element),
+	}
+}
+
+func (p *lruPolicy[K]) Add(key K) {
+	if _, ok := p.items[key]; ok {
+		return
+	}
+	p.items[key] = p.ll.PushFront(key)
+}
+
+func (p *lruPolicy[K]) Access(key K) {
+	if !p.touch {
+		return
+	}
+	if elem, ok := p.items[key]; ok {
+		p.ll.MoveToFront(elem)
+	}
+}
+
+func (p *lruPolicy[K]) Remove(key K) {
+	if elem, ok := p.items[key]; ok {
+		p.ll.Remove(elem)
+		delete(p.items, key)
+	}
+}
+
+func (p *lruPolicy[K]) Victim() (K, bool) {
+	elem := p.ll.Back()
+	if elem == nil {
+		var zero K
+		return zero, false
+	}
+	return elem.Value.(K), true
+}
+
+func (p *lruPolicy[K]) victims() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for elem := p.ll.Back(); elem != nil; elem = elem.Prev() {
+			if !yield(elem.Value.(K)) {
+				return
+			}
+		}
+	}
+}
+
+// ---------------- LFU ---------------- //
+
+type lfuNode[K comparable] struct {
+	key  K
+	freq int
+	elem *list.Element
+}
+
+// lfuPolicy 最不经常使用，访问次数相同时淘汰最久未访问的 key
+type lfuPolicy[K comparable] struct {
+	items   map[K]*lfuNode[K]
+	freqs   map[int]*list.List
+	minFreq int
+}
+
+// NewLFUPolicy 淘汰访问次数最少的 key，次数相同时淘汰最久未访问的
+func NewLFUPolicy[K comparable]() EvictPolicy[K] {
+	return &lfuPolicy[K]{
+		items: make(map[K]*lfuNode[K]),
+		freqs: make(map[int]*list.List),
+	}
+}
+
+func (p *lfuPolicy[K]) Add(key K) {
+	if _, ok := p.items[key]; ok {
+		return
+	}
+	node := &lfuNode[K]{key: key, freq: 1}
+	node.elem = p.freqList(1).PushFront(node)
+	p.items[key] = node
+	p.minFreq = 1
+}
+
+func (p *lfuPolicy[K]) Access(key K) {
+	node, ok := p.items[key]
+	if !ok {
+		return
+	}
+	p.unlink(node)
+	node.freq++
+	node.elem = p.freqList(node.freq).PushFront(node)
+}
+
+func (p *lfuPolicy[K]) Remove(key K) {
+	node, ok := p.items[key]
+	if !ok {
+		return
+	}
+	p.unlink(node)
+	delete(p.items, key)
+}
+
+func (p *lfuPolicy[K]) Victim() (K, bool) {
+	var zero K
+	if len(p.items) == 0 {
+		return zero, false
+	}
+	// Remove 可能使 minFreq 失效，此时重新查找最小频次
+	if _, ok := p.freqs[p.minFreq]; !ok {
+		p.minFreq = 0
+		for freq := range p.freqs {
+			if p.minFreq == 0 || freq < p.minFreq {
+				p.minFreq = freq
+			}
+		}
+	}
+	return p.freqs[p.minFreq].Back().Value.(*lfuNode[K]).key, true
+}
+
+// victims 按访问次数从小到大返回，恢复后访问次数重新从 1 开始计算
+func (p *lfuPolicy[K]) victims() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		freqs := make([]int, 0, len(p.freqs))
+		for freq := range p.freqs {
+			freqs = append(freqs, freq)
+		}
+		slices.Sort(freqs)
+		for _, freq := range freqs {
+			for elem := p.freqs[freq].Back(); elem != nil; elem = elem.Prev() {
+				if !yield(elem.Value.(*lfuNode[K]).key) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *lfuPolicy[K]) freqList(freq int) *list.List {
+	l, ok := p.freqs[freq]
+	if !ok {
+		l = list.New()
+		p.freqs[freq] = l
+	}
+	return l
+}
+
+// unlink 将 node 从所在频次链表中移除，链表为空时一并删除
+func (p *lfuPolicy[K]) unlink(node *lfuNode[K]) {
+	l := p.freqs[node.freq]
+	l.Remove(node.elem)
+	if l.Len() == 0 {
+		delete(p.freqs, node.freq)
+		if p.minFreq == node.freq {
+			p.minFreq++
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package maps
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardMapEviction(t *testing.T) {
+	testCase := []struct {
+		name        string
+		policy      func() EvictPolicy[string]
+		wantEvicted []string
+		wantKeys    []string
+	}{
+		{
+			// 访问 a 之后，b 成为最久未访问的 key
+			name:        "lru",
+			policy:      NewLRUPolicy[string],
+			wantEvicted: []string{"b"},
+			wantKeys:    []string{"a", "c", "d"},
+		},
+		{
+			// 访问不影响 FIFO 的顺序，最早写入的 a 被淘汰
+			name:        "fifo",
+			policy:      NewFIFOPolicy[string],
+			wantEvicted: []string{"a"},
+			wantKeys:    []string{"b", "c", "d"},
+		},
+		{
+			// a 访问过两次，b 和 c 各一次，其中 b 更久未访问
+			name:        "lfu",
+			policy:      NewLFUPolicy[string],
+			wantEvicted: []string{"b"},
+			wantKeys:    []string{"a", "c", "d"},
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			var evicted []string
+			m := NewShardMap[string, int](
+				WithShardCount(1),
+				WithCapacity(3),
+				WithEvictPolicy(tc.policy),
+				WithOnEvict(func(key string, val int) {
+					evicted = append(evicted, key)
+				}),
+			)
+			m.Set("a", 1)
+			m.Set("b", 2)
+			m.Set("c", 3)
+			m.Get("a")
+			m.Get("c")
+			m.Get("a")
+			m.Set("d", 4)
+
+			keys := m.Keys()
+			sort.Strings(keys)
+			assert.Equal(t, tc.wantEvicted, evicted)
+			assert.Equal(t, tc.wantKeys, keys)
+			assert.Equal(t, 3, m.Len())
+		})
+	}
+}
+
+func TestShardMapEvictionOverwrite(t *testing.T) {
+	var evicted int
+	m := NewShardMap[int, int](
+		WithShardCount(1),
+		WithCapacity(2),
+		WithOnEvict(func(key int, val int) { evicted++ }),
+	)
+	m.Set(1, 1)
+	m.Set(2, 2)
+	// 覆盖已存在的 key 不会触发淘汰
+	m.Set(1, 10)
+	m.Set(2, 20)
+	assert.Equal(t, 0, evicted)
+
+	m.Set(3, 3)
+	assert.Equal(t, 1, evicted)
+	_, ok := m.Get(1)
+	assert.False(t, ok)
+}
+
+func TestShardMapCapacity(t *testing.T) {
+	testCase := []struct {
+		name     string
+		shardCnt uint64
+		capacity uint64
+		resize   uint64
+	}{
+		{name: "uneven split", shardCnt: 4, capacity: 10},
+		{name: "default shards capacity 1", capacity: 1},
+		{name: "default shards capacity 10", capacity: 10},
+		{name: "more shards than capacity", shardCnt: 64, capacity: 5},
+		{name: "resize beyond capacity", shardCnt: 2, capacity: 6, resize: 32},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := []Option{WithCapacity(tc.capacity)}
+			if tc.shardCnt > 0 {
+				opts = append(opts, WithShardCount(tc.shardCnt))
+			}
+			m := NewShardMap[int, int](opts...)
+			m.Resize(tc.resize)
+			assert.LessOrEqual(t, uint64(m.ShardCount()), tc.capacity)
+			for i := 0; i < 1000; i++ {
+				m.Set(i, i)
+				assert.LessOrEqual(t, m.Len(), int(tc.capacity))
+			}
+			// 最近写入的 key 一定存在
+			_, ok := m.Get(999)
+			assert.True(t, ok)
+			assert.Equal(t, len(m.Keys()), m.Len())
+		})
+	}
+}
+
+func TestShardMapEvictAfterDelete(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(1), WithCapacity(2), WithEvictPolicy(NewLFUPolicy[int]))
+	m.Set(1, 1)
+	m.Set(2, 2)
+	m.Get(2)
+	m.Delete(1)
+	m.Set(3, 3)
+	m.Set(4, 4)
+
+	_, ok := m.Get(3)
+	assert.False(t, ok)
+	_, ok = m.Get(2)
+	assert.True(t, ok)
+}
+
+func TestShardMapOptionTypeMismatch(t *testing.T) {
+	assert.Panics(t, func() {
+		NewShardMap[string, int](WithShardCount(1), WithCapacity(1), WithEvictPolicy(NewLRUPolicy[int]))
+	})
+	assert.Panics(t, func() {
+		NewShardMap[string, int](WithShardCount(1), WithOnEvict(func(key string, val string) {}))
+	})
+}
@@ -0,0 +1,151 @@
+package maps
+
+import "iter"
+
+// 对内置 map 的简单封装，实现 Map 接口
+// 不是并发安全的！！！
+
+type HashMap[K comparable, V any] struct {
+	container map[K]V
+}
+
+func NewHashMap[K comparable, V any]() *HashMap[K, V] {
+	return &HashMap[K, V]{
+		container: make(map[K]V),
+	}
+}
+
+func (m *HashMap[K, V]) Set(key K, val V) {
+	m.container[key] = val
+}
+
+func (m *HashMap[K, V]) Get(key K) (V, bool) {
+	v, ok := m.container[key]
+	return v, ok
+}
+
+func (m *HashMap[K, V]) Delete(key K) {
+	delete(m.container, key)
+}
+
+func (m *HashMap[K, V]) Len() int {
+	return len(m.container)
+}
+
+func (m *HashMap[K, V]) Keys() []K {
+	keys := make([]K, 0, len(m.container))
+	for key := range m.container {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (m *HashMap[K, V]) Values() []V {
+	values := make([]V, 0, len(m.container))
+	for _, v := range m.container {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Range 遍历所有键值, f返回true则停止。与内置 map 一致，回调中可以 Delete，
+// 回调中新增的 key 不保证会被遍历到
+func (m *HashMap[K, V]) Range(f func(key K, value V) (stop bool)) {
+	for k, v := range m.container {
+		if f(k, v) {
+			return
+		}
+	}
+}
+
+// All 返回遍历所有键值的迭代器，与内置 map 的遍历语义一致
+func (m *HashMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range m.container {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// KeysSeq 返回遍历所有 key 的迭代器
+func (m *HashMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.container {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesSeq 返回遍历所有 value 的迭代器
+func (m *HashMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.container {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// GetOrSet key 存在时返回已有的值，loaded 为 true；否则写入 val 并返回 val，loaded 为 false
+func (m *HashMap[K, V]) GetOrSet(key K, val V) (actual V, loaded bool) {
+	if old, ok := m.container[key]; ok {
+		return old, true
+	}
+	m.container[key] = val
+	return val, false
+}
+
+// LoadAndDelete 删除 key 并返回删除前的值
+func (m *HashMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	val, ok := m.container[key]
+	if ok {
+		delete(m.container, key)
+	}
+	return val, ok
+}
+
+// CompareAndSwapFunc 当前值与 old 按 eq 比较相等时替换为 new
+func (m *HashMap[K, V]) CompareAndSwapFunc(key K, old, new V, eq func(a, b V) bool) (swapped bool) {
+	cur, ok := m.container[key]
+	if !ok || !eq(cur, old) {
+		return false
+	}
+	m.container[key] = new
+	return true
+}
+
+// CompareAndDeleteFunc 当前值与 old 按 eq 比较相等时删除 key
+func (m *HashMap[K, V]) CompareAndDeleteFunc(key K, old V, eq func(a, b V) bool) (deleted bool) {
+	cur, ok := m.container[key]
+	if !ok || !eq(cur, old) {
+		return false
+	}
+	delete(m.container, key)
+	return true
+}
+
+// Compute 根据旧值计算新值：fn 返回 keep 为 false 时删除 key，否则写入 newVal
+func (m *HashMap[K, V]) Compute(key K, fn func(old V, ok bool) (newVal V, keep bool)) (V, bool) {
+	old, ok := m.container[key]
+	newVal, keep := fn(old, ok)
+	if !keep {
+		delete(m.container, key)
+		var zero V
+		return zero, false
+	}
+	m.container[key] = newVal
+	return newVal, true
+}
+
+// Upsert 插入或更新：fn 根据 key 是否存在、旧值和传入的 val 计算最终写入的值并返回
+func (m *HashMap[K, V]) Upsert(key K, val V, fn func(exist bool, old V, new V) V) V {
+	old, ok := m.container[key]
+	res := fn(ok, old, val)
+	m.container[key] = res
+	return res
+}
@@ -0,0 +1,33 @@
+package maps
+
+import "iter"
+
+// Map ConcurrentMap、ShardMap 和 HashMap 共同实现的接口，
+// 调用方依赖该接口即可在不同实现之间切换
+type Map[K comparable, V any] interface {
+	Set(key K, val V)
+	Get(key K) (V, bool)
+	Delete(key K)
+	Len() int
+	Keys() []K
+	Values() []V
+	// Range 遍历所有键值, f返回true则停止
+	Range(f func(key K, value V) (stop bool))
+	All() iter.Seq2[K, V]
+	KeysSeq() iter.Seq[K]
+	ValuesSeq() iter.Seq[V]
+
+	GetOrSet(key K, val V) (actual V, loaded bool)
+	LoadAndDelete(key K) (V, bool)
+	// V 可比较时使用包级的 CompareAndSwap/CompareAndDelete
+	CompareAndSwapper[K, V]
+	Compute(key K, fn func(old V, ok bool) (newVal V, keep bool)) (V, bool)
+	Upsert(key K, val V, fn func(exist bool, old V, new V) V) V
+}
+
+var (
+	_ Map[string, int] = (*HashMap[string, int])(nil)
+	_ Map[string, int] = (*ConcurrentMap[string, int])(nil)
+	_ Map[string, int] = (*ShardMap[string, int])(nil)
+	_ Map[string, int] = (*RCUMap[string, int])(nil)
+)
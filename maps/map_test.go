@@ -0,0 +1,222 @@
+package maps
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/Ri0nGo/gokit/slice"
+	"github.com/stretchr/testify/assert"
+)
+
+// mapImpls 所有 Map 实现，新增实现时需要加到这里，以通过同一套一致性测试
+func mapImpls() map[string]func() Map[string, int] {
+	return map[string]func() Map[string, int]{
+		"HashMap":       func() Map[string, int] { return NewHashMap[string, int]() },
+		"ConcurrentMap": func() Map[string, int] { return NewConcurrentMap[string, int]() },
+		"ShardMap":      func() Map[string, int] { return NewShardMap[string, int](WithShardCount(4)) },
+		"RCUMap":        func() Map[string, int] { return NewRCUMap[string, int]() },
+	}
+}
+
+func TestMapConformance(t *testing.T) {
+	for name, newMap := range mapImpls() {
+		t.Run(name, func(t *testing.T) {
+			testMapConformance(t, newMap)
+		})
+	}
+}
+
+// testMapConformance Map 接口的一致性测试
+func testMapConformance(t *testing.T, newMap func() Map[string, int]) {
+	t.Run("set get delete", func(t *testing.T) {
+		m := newMap()
+		_, ok := m.Get("a")
+		assert.False(t, ok)
+
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("a", 3)
+		val, ok := m.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, 3, val)
+		assert.Equal(t, 2, m.Len())
+
+		m.Delete("a")
+		m.Delete("not exist")
+		_, ok = m.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 1, m.Len())
+	})
+
+	t.Run("keys values", func(t *testing.T) {
+		m := newMap()
+		assert.Empty(t, m.Keys())
+		assert.Empty(t, m.Values())
+
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		keys := m.Keys()
+		values := m.Values()
+		sort.Strings(keys)
+		sort.Ints(values)
+		assert.Equal(t, []string{"a", "b", "c"}, keys)
+		assert.Equal(t, []int{1, 2, 3}, values)
+	})
+
+	t.Run("range", func(t *testing.T) {
+		m := newMap()
+		for i, key := range []string{"a", "b", "c", "d"} {
+			m.Set(key, i)
+		}
+		got := make(map[string]int)
+		m.Range(func(key string, value int) bool {
+			got[key] = value
+			return false
+		})
+		assert.Equal(t, map[string]int{"a": 0, "b": 1, "c": 2, "d": 3}, got)
+
+		var visited int
+		m.Range(func(key string, value int) bool {
+			visited++
+			return visited == 2
+		})
+		assert.Equal(t, 2, visited)
+	})
+
+	t.Run("delete in range", func(t *testing.T) {
+		m := newMap()
+		for i, key := range []string{"a", "b", "c", "d"} {
+			m.Set(key, i)
+		}
+		m.Range(func(key string, value int) bool {
+			m.Delete(key)
+			return false
+		})
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("iterators", func(t *testing.T) {
+		m := newMap()
+		for i, key := range []string{"a", "b", "c", "d"} {
+			m.Set(key, i)
+		}
+		got := make(map[string]int)
+		for k, v := range m.All() {
+			got[k] = v
+		}
+		assert.Equal(t, map[string]int{"a": 0, "b": 1, "c": 2, "d": 3}, got)
+
+		keys := slice.Collect(m.KeysSeq())
+		values := slice.Collect(m.ValuesSeq())
+		sort.Strings(keys)
+		sort.Ints(values)
+		assert.Equal(t, []string{"a", "b", "c", "d"}, keys)
+		assert.Equal(t, []int{0, 1, 2, 3}, values)
+
+		var visited int
+		for range m.All() {
+			visited++
+			if visited == 2 {
+				break
+			}
+		}
+		assert.Equal(t, 2, visited)
+
+		for k := range m.All() {
+			m.Delete(k)
+		}
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("get or set", func(t *testing.T) {
+		m := newMap()
+		val, loaded := m.GetOrSet("a", 1)
+		assert.False(t, loaded)
+		assert.Equal(t, 1, val)
+		val, loaded = m.GetOrSet("a", 2)
+		assert.True(t, loaded)
+		assert.Equal(t, 1, val)
+		assert.Equal(t, 1, m.Len())
+	})
+
+	t.Run("load and delete", func(t *testing.T) {
+		m := newMap()
+		m.Set("a", 10)
+		val, ok := m.LoadAndDelete("a")
+		assert.True(t, ok)
+		assert.Equal(t, 10, val)
+		_, ok = m.LoadAndDelete("a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("compare and swap", func(t *testing.T) {
+		m := newMap()
+		m.Set("a", 1)
+		assert.False(t, CompareAndSwap(m, "a", 2, 3))
+		assert.True(t, CompareAndSwap(m, "a", 1, 3))
+		assert.False(t, CompareAndSwap(m, "missing", 0, 3))
+		val, _ := m.Get("a")
+		assert.Equal(t, 3, val)
+		assert.Equal(t, 1, m.Len())
+	})
+
+	t.Run("compare and delete", func(t *testing.T) {
+		m := newMap()
+		m.Set("a", 3)
+		assert.False(t, CompareAndDelete(m, "a", 1))
+		assert.False(t, CompareAndDelete(m, "missing", 0))
+		assert.True(t, CompareAndDelete(m, "a", 3))
+		_, ok := m.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("compute", func(t *testing.T) {
+		m := newMap()
+		val, ok := m.Compute("c", func(old int, ok bool) (int, bool) {
+			assert.False(t, ok)
+			return old + 5, true
+		})
+		assert.True(t, ok)
+		assert.Equal(t, 5, val)
+
+		val, ok = m.Compute("c", func(old int, ok bool) (int, bool) {
+			assert.True(t, ok)
+			return old * 2, true
+		})
+		assert.True(t, ok)
+		assert.Equal(t, 10, val)
+
+		_, ok = m.Compute("c", func(old int, ok bool) (int, bool) {
+			return 0, false
+		})
+		assert.False(t, ok)
+		_, ok = m.Get("c")
+		assert.False(t, ok)
+		assert.Equal(t, 0, m.Len())
+
+		// 不存在的 key 且 keep 为 false 时不做任何修改
+		_, ok = m.Compute("d", func(old int, ok bool) (int, bool) {
+			return 0, false
+		})
+		assert.False(t, ok)
+		assert.Equal(t, 0, m.Len())
+	})
+
+	t.Run("upsert", func(t *testing.T) {
+		m := newMap()
+		add := func(exist bool, old int, new int) int {
+			if exist {
+				return old + new
+			}
+			return new
+		}
+		assert.Equal(t, 2, m.Upsert("d", 2, add))
+		assert.Equal(t, 5, m.Upsert("d", 3, add))
+		val, _ := m.Get("d")
+		assert.Equal(t, 5, val)
+		assert.Equal(t, 1, m.Len())
+	})
+}
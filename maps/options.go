@@ -0,0 +1,147 @@
+package maps
+
+// Option 用于配置 NewShardMap 创建的 ShardMap
+type Option func(*options)
+
+type options struct {
+	shardCnt     uint64
+	initCapacity uint64
+	hasher       any // func(K) uint64
+	capacity     uint64
+	newPolicy    any // func() EvictPolicy[K]
+	onEvict      any // func(K, V)
+	maxLoad      uint64
+	stats        bool
+
+	// 以下配置仅对 Open 打开的持久化 ShardMap 生效
+	syncPolicy       SyncPolicy
+	compactThreshold uint64
+}
+
+func parseOptions(opts []Option) *options {
+	o := &options{
+		shardCnt:         defaultShardCnt,
+		compactThreshold: defaultCompactThreshold,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithShardCount 设置分片数，实际分片数为 >= shardCnt 的最小 2^n（传入 0 时使用默认值 16）
+func WithShardCount(shardCnt uint64) Option {
+	return func(o *options) {
+		if shardCnt > 0 {
+			o.shardCnt = shardCnt
+		}
+	}
+}
+
+// WithInitialCapacity 预估的元素总数，用于预分配各分片 map 的容量
+func WithInitialCapacity(n uint64) Option {
+	return func(o *options) {
+		o.initCapacity = n
+	}
+}
+
+// WithHasher 自定义 key 的哈希函数，用于决定 key 落在哪个分片
+func WithHasher[K comparable](hasher func(K) uint64) Option {
+	return func(o *options) {
+		o.hasher = hasher
+	}
+}
+
+// WithCapacity 设置最大元素个数，按分片拆分，各分片容量之和恰好等于 capacity；
+// 分片数大于 capacity 时会减少到不超过 capacity 的最大 2^n。
+// 分片满时按淘汰策略淘汰元素，未指定策略时默认使用 LRU
+func WithCapacity(capacity uint64) Option {
+	return func(o *options) {
+		o.capacity = capacity
+	}
+}
+
+// WithEvictPolicy 设置淘汰策略，newPolicy 会为每个分片各创建一个实例
+// 例如：WithEvictPolicy(NewLFUPolicy[string])
+func WithEvictPolicy[K comparable](newPolicy func() EvictPolicy[K]) Option {
+	return func(o *options) {
+		o.newPolicy = newPolicy
+	}
+}
+
+// WithOnEvict 设置因容量不足而淘汰元素时的回调，回调在分片锁释放后执行
+func WithOnEvict[K comparable, V any](fn func(key K, val V)) Option {
+	return func(o *options) {
+		o.onEvict = fn
+	}
+}
+
+// WithAutoGrow 开启自动扩容：当某个分片的元素个数超过 maxLoad 时，
+// 在后台将分片数翻倍（最多扩容到 maxAutoShardCnt 个分片）
+func WithAutoGrow(maxLoad uint64) Option {
+	return func(o *options) {
+		o.maxLoad = maxLoad
+	}
+}
+
+// WithStats 开启命中率和锁等待时间的统计，可通过 ShardMap.Stats 获取。
+// 每次加锁都会额外调用 time.Now，因此默认关闭
+func WithStats() Option {
+	return func(o *options) {
+		o.stats = true
+	}
+}
+
+// WithSyncPolicy 设置持久化 ShardMap 写入 WAL 后的 fsync 策略，默认 SyncEverySecond
+func WithSyncPolicy(policy SyncPolicy) Option {
+	return func(o *options) {
+		o.syncPolicy = policy
+	}
+}
+
+// WithCompactThreshold WAL 中累计写入 n 条记录后，在后台将数据压缩为快照并清空 WAL；
+// 传入 0 表示只在调用 Compact 时压缩
+func WithCompactThreshold(n uint64) Option {
+	return func(o *options) {
+		o.compactThreshold = n
+	}
+}
+
+// hasherFunc 取出哈希函数，未设置时使用 defaultHasher，类型与 K 不匹配时 panic
+func hasherFunc[K comparable](o *options) func(K) uint64 {
+	if o.hasher == nil {
+		return defaultHasher[K]
+	}
+	f, ok := o.hasher.(func(K) uint64)
+	if !ok {
+		panic("maps: WithHasher key type does not match ShardMap")
+	}
+	return f
+}
+
+// newPolicyFunc 取出淘汰策略的构造函数，类型与 K 不匹配时 panic
+func newPolicyFunc[K comparable](o *options) func() EvictPolicy[K] {
+	if o.capacity == 0 {
+		return nil
+	}
+	if o.newPolicy == nil {
+		return NewLRUPolicy[K]
+	}
+	f, ok := o.newPolicy.(func() EvictPolicy[K])
+	if !ok {
+		panic("maps: WithEvictPolicy key type does not match ShardMap")
+	}
+	return f
+}
+
+// onEvictFunc 取出淘汰回调，类型与 K, V 不匹配时 panic
+func onEvictFunc[K comparable, V any](o *options) func(K, V) {
+	if o.onEvict == nil {
+		return nil
+	}
+	f, ok := o.onEvict.(func(K, V))
+	if !ok {
+		panic("maps: WithOnEvict key/value type does not match ShardMap")
+	}
+	return f
+}
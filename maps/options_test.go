@@ -0,0 +1,90 @@
+package maps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardMapShardCount(t *testing.T) {
+	testCase := []struct {
+		name      string
+		opts      []Option
+		wantCount uint64
+	}{
+		{
+			name:      "default",
+			wantCount: defaultShardCnt,
+		},
+		{
+			name:      "zero use default",
+			opts:      []Option{WithShardCount(0)},
+			wantCount: defaultShardCnt,
+		},
+		{
+			name:      "round up to power of 2",
+			opts:      []Option{WithShardCount(5)},
+			wantCount: 8,
+		},
+		{
+			name:      "power of 2",
+			opts:      []Option{WithShardCount(32)},
+			wantCount: 32,
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewShardMap[string, int](tc.opts...)
+			assert.Equal(t, int(tc.wantCount), m.ShardCount())
+		})
+	}
+}
+
+func TestShardMapWithHasher(t *testing.T) {
+	m := NewShardMap[string, int](
+		WithShardCount(8),
+		WithInitialCapacity(64),
+		WithHasher(func(key string) uint64 { return 3 }),
+	)
+	for _, key := range []string{"a", "b", "c"} {
+		m.Set(key, 1)
+	}
+	assert.Equal(t, 3, len(m.table.Load().shards[3].container))
+	assert.Equal(t, 3, m.Len())
+
+	assert.Panics(t, func() {
+		NewShardMap[string, int](WithHasher(func(key int) uint64 { return 0 }))
+	})
+}
+
+func TestDefaultHasherStructKey(t *testing.T) {
+	type point struct {
+		X, Y int
+		Name string
+	}
+	a := point{X: 1, Y: 2, Name: "a"}
+	b := point{X: 1, Y: 2, Name: "a"}
+	c := point{X: 2, Y: 1, Name: "a"}
+
+	assert.Equal(t, defaultHasher(a), defaultHasher(b))
+	assert.NotEqual(t, defaultHasher(a), defaultHasher(c))
+
+	m := NewShardMap[point, int]()
+	m.Set(a, 1)
+	val, ok := m.Get(b)
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+}
+
+func BenchmarkDefaultHasherStruct(b *testing.B) {
+	type point struct {
+		X, Y int
+		Name string
+	}
+	p := point{X: 1, Y: 2, Name: "gokit"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		defaultHasher(p)
+	}
+}
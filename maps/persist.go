@@ -0,0 +1,271 @@
+package maps
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	stdmaps "maps"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 持久化 ShardMap：通过 Open 打开，每次 Set/Delete（包括淘汰）都会追加到 WAL 中，
+// WAL 达到一定长度后压缩为快照。重启时加载快照并回放之后的 WAL 即可恢复数据
+
+const (
+	snapshotFile            = "snapshot"
+	defaultCompactThreshold = 10000
+)
+
+// SyncPolicy 写入 WAL 后的 fsync 策略
+type SyncPolicy int
+
+const (
+	// SyncEverySecond 每秒 fsync 一次，进程崩溃不会丢数据，机器掉电最多丢失约 1 秒的写入（默认）
+	SyncEverySecond SyncPolicy = iota
+	// SyncAlways 每次写入后都 fsync，最安全也最慢。写入在 fsync 完成前一直持有所在分片的锁，
+	// 同一分片的写入吞吐受限于磁盘的 fsync 延迟；不同分片的并发写入会共用一次 fsync
+	SyncAlways
+	// SyncNever 只写入操作系统缓存，由操作系统决定何时落盘
+	SyncNever
+)
+
+var (
+	ErrClosed        = errors.New("maps: persistent ShardMap is closed")
+	errNotPersistent = errors.New("maps: ShardMap is not opened by Open")
+)
+
+// snapshotGob 快照文件格式，Gen 表示快照已经包含了所有序号小于 Gen 的 WAL 文件。
+// Entries 按淘汰顺序保存（先被淘汰的在前），恢复时按顺序写入，超出容量时淘汰的 key 是确定的
+type snapshotGob[K comparable, V any] struct {
+	Gen     uint64
+	Entries []snapshotEntry[K, V]
+}
+
+// snapshotEntry ExpireAt 为 0 表示永不过期
+type snapshotEntry[K comparable, V any] struct {
+	Key      K
+	Val      V
+	ExpireAt int64
+}
+
+// Open 打开目录 dir 下的持久化 ShardMap，目录不存在时会创建。
+// 打开时会加载快照并回放 WAL，然后立即压缩一次。K、V 需要能被 gob 编码，
+// 同一个目录同一时刻只能被一个 ShardMap 打开。每次写入都会额外编码并写一次文件，
+// 使用 SyncAlways 时还要等待 fsync，写入吞吐远低于非持久化的 ShardMap
+func Open[K comparable, V any](dir string, opts ...Option) (*ShardMap[K, V], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	o := parseOptions(opts)
+	s := newShardMap[K, V](o)
+
+	lastGen, err := s.recover(dir)
+	if err != nil {
+		return nil, err
+	}
+	// 将恢复出的数据写成新的快照，之后的写入记录到新的 WAL 中
+	gen := lastGen + 1
+	snap := s.snapshotLocked()
+	snap.Gen = gen
+	if err := writeSnapshot(dir, snap); err != nil {
+		return nil, err
+	}
+	if err := removeWALBefore(dir, gen); err != nil {
+		return nil, err
+	}
+	w, err := openWAL[K, V](dir, gen, o, s.Compact)
+	if err != nil {
+		return nil, err
+	}
+	s.wal = w
+	return s, nil
+}
+
+// Compact 将当前数据写成快照并切换到新的 WAL 文件，旧的 WAL 在快照落盘后删除。
+// 切换 WAL 时会短暂锁住所有分片
+func (s *ShardMap[K, V]) Compact() error {
+	w := s.wal
+	if w == nil {
+		return errNotPersistent
+	}
+	w.compactMu.Lock()
+	defer w.compactMu.Unlock()
+
+	t := s.lockAllShards()
+	snap := s.snapshotLocked()
+	gen, err := w.rotate()
+	s.unlockAllShards(t)
+	if err != nil {
+		return err
+	}
+
+	snap.Gen = gen
+	if err := writeSnapshot(w.dir, snap); err != nil {
+		return err
+	}
+	return removeWALBefore(w.dir, gen)
+}
+
+// Sync 立即将 WAL 落盘，并返回之前写日志或后台压缩时发生的错误
+func (s *ShardMap[K, V]) Sync() error {
+	if s.wal == nil {
+		return errNotPersistent
+	}
+	return s.wal.sync()
+}
+
+// Err 返回写日志或后台压缩时发生的错误，与 Sync 不同，不会落盘也不会清空后台压缩的错误。
+// 写日志失败后之后的写入只修改内存，不再持久化；关闭后返回 ErrClosed，非持久化的 ShardMap 返回 nil
+func (s *ShardMap[K, V]) Err() error {
+	if s.wal == nil {
+		return nil
+	}
+	return s.wal.error()
+}
+
+// Close 等待进行中的压缩结束后将 WAL 落盘并关闭文件，关闭后 ShardMap 仍可在内存中使用，但写入不再持久化。
+// 非持久化的 ShardMap 调用 Close 不做任何事
+func (s *ShardMap[K, V]) Close() error {
+	w := s.wal
+	if w == nil {
+		return nil
+	}
+	return w.close()
+}
+
+// recover 加载快照并回放 WAL，返回最后一个 WAL 文件的序号
+func (s *ShardMap[K, V]) recover(dir string) (uint64, error) {
+	snap, err := readSnapshot[K, V](dir)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UnixNano()
+	for _, e := range snap.Entries {
+		if e.ExpireAt > 0 && now >= e.ExpireAt {
+			continue
+		}
+		s.apply(&walRecord[K, V]{Op: walSet, Key: e.Key, Val: e.Val, ExpireAt: e.ExpireAt})
+	}
+
+	gens, err := listWAL(dir)
+	if err != nil {
+		return 0, err
+	}
+	lastGen := snap.Gen
+	for _, gen := range gens {
+		// 快照已经包含了这些 WAL 中的数据
+		if gen < snap.Gen {
+			continue
+		}
+		if err := replayWAL(walPath(dir, gen), s.apply); err != nil {
+			return 0, err
+		}
+		lastGen = gen
+	}
+	return lastGen, nil
+}
+
+// apply 回放一条记录，此时 wal 尚未打开，不会再次写入日志
+func (s *ShardMap[K, V]) apply(rec *walRecord[K, V]) {
+	if rec.Op == walClear {
+		t := s.lockAllShards()
+		s.clearLocked(t)
+		s.unlockAllShards(t)
+		return
+	}
+	sh := s.lockShard(rec.Key)
+	_, existed := sh.container[rec.Key]
+	switch {
+	case rec.Op == walSet && (rec.ExpireAt == 0 || time.Now().UnixNano() < rec.ExpireAt):
+		s.setLocked(sh, rec.Key, rec.Val, rec.ExpireAt)
+	case existed:
+		// 删除，或写入的值已经过期
+		s.removeLocked(sh, rec.Key)
+	}
+	sh.mu.Unlock()
+}
+
+// snapshotLocked 复制所有未过期的数据，调用方需持有所有分片的锁或确保没有并发写入。
+// 淘汰策略实现了 orderedPolicy 时每个分片按淘汰顺序保存，否则按 map 的遍历顺序
+func (s *ShardMap[K, V]) snapshotLocked() *snapshotGob[K, V] {
+	snap := &snapshotGob[K, V]{
+		Entries: make([]snapshotEntry[K, V], 0, s.Len()),
+	}
+	now := time.Now().UnixNano()
+	for _, sh := range s.table.Load().shards {
+		keys := stdmaps.Keys(sh.container)
+		if p, ok := sh.policy.(orderedPolicy[K]); ok {
+			keys = p.victims()
+		}
+		for k := range keys {
+			if sh.expired(k, now) {
+				continue
+			}
+			snap.Entries = append(snap.Entries, snapshotEntry[K, V]{Key: k, Val: sh.container[k], ExpireAt: sh.expires[k]})
+		}
+	}
+	return snap
+}
+
+// readSnapshot 读取快照，快照不存在时返回空快照
+func readSnapshot[K comparable, V any](dir string) (*snapshotGob[K, V], error) {
+	data, err := os.ReadFile(filepath.Join(dir, snapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return &snapshotGob[K, V]{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snap snapshotGob[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+// writeSnapshot 先写临时文件再重命名，保证快照文件要么是旧的要么是完整的新快照
+func writeSnapshot[K comparable, V any](dir string, snap *snapshotGob[K, V]) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(snap); err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, snapshotFile+".tmp")
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, snapshotFile)); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// removeWALBefore 删除序号小于 gen 的 WAL 文件
+func removeWALBefore(dir string, gen uint64) error {
+	gens, err := listWAL(dir)
+	if err != nil {
+		return err
+	}
+	for _, g := range gens {
+		if g >= gen {
+			break
+		}
+		if err := os.Remove(walPath(dir, g)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package maps
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardMapPersist(t *testing.T) {
+	testCase := []struct {
+		name   string
+		policy SyncPolicy
+	}{
+		{name: "every second", policy: SyncEverySecond},
+		{name: "always", policy: SyncAlways},
+		{name: "never", policy: SyncNever},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			m, err := Open[string, int](dir, WithShardCount(4), WithSyncPolicy(tc.policy))
+			assert.NoError(t, err)
+			m.Set("a", 1)
+			m.Set("b", 2)
+			m.Set("c", 3)
+			m.Delete("b")
+			m.Compute("c", func(old int, ok bool) (int, bool) {
+				return old * 10, true
+			})
+			assert.NoError(t, m.Close())
+
+			m, err = Open[string, int](dir, WithShardCount(4))
+			assert.NoError(t, err)
+			defer m.Close()
+			assert.Equal(t, 2, m.Len())
+			val, ok := m.Get("a")
+			assert.True(t, ok)
+			assert.Equal(t, 1, val)
+			_, ok = m.Get("b")
+			assert.False(t, ok)
+			val, _ = m.Get("c")
+			assert.Equal(t, 30, val)
+		})
+	}
+}
+
+func TestShardMapPersistTTL(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Open[string, int](dir)
+	assert.NoError(t, err)
+	m.SetWithTTL("short", 1, 10*time.Millisecond)
+	m.SetWithTTL("long", 2, time.Minute)
+	assert.NoError(t, m.Close())
+	time.Sleep(30 * time.Millisecond)
+
+	m, err = Open[string, int](dir)
+	assert.NoError(t, err)
+	defer m.Close()
+	assert.Equal(t, 1, m.Len())
+	_, ttl, ok := m.GetWithTTL("long")
+	assert.True(t, ok)
+	assert.True(t, ttl > 0 && ttl <= time.Minute)
+}
+
+func TestShardMapCompact(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Open[int, int](dir, WithCompactThreshold(100))
+	assert.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		m.Set(i%50, i)
+	}
+	assert.NoError(t, m.Compact())
+	assert.NoError(t, m.Sync())
+
+	// 压缩后只保留最新的 WAL 文件
+	gens, err := listWAL(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(gens))
+	for i := 1000; i < 1010; i++ {
+		m.Set(i, i)
+	}
+	assert.NoError(t, m.Close())
+
+	m, err = Open[int, int](dir)
+	assert.NoError(t, err)
+	defer m.Close()
+	assert.Equal(t, 60, m.Len())
+	for i := 0; i < 50; i++ {
+		val, ok := m.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, 950+i, val)
+	}
+}
+
+func TestShardMapPersistConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Open[int, int](dir, WithShardCount(8), WithSyncPolicy(SyncAlways), WithCompactThreshold(50))
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				m.Set(g*100+i, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	// Close 会等待后台压缩结束
+	assert.NoError(t, m.Close())
+	assert.False(t, m.wal.compacting.Load())
+
+	m, err = Open[int, int](dir, WithShardCount(8))
+	assert.NoError(t, err)
+	defer m.Close()
+	assert.Equal(t, 800, m.Len())
+	for g := 0; g < 8; g++ {
+		val, ok := m.Get(g*100 + 99)
+		assert.True(t, ok)
+		assert.Equal(t, 99, val)
+	}
+}
+
+func TestShardMapPersistTornTail(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Open[string, int](dir, WithSyncPolicy(SyncAlways))
+	assert.NoError(t, err)
+	m.Set("a", 1)
+	m.Set("b", 2)
+	gen := m.wal.gen
+	assert.NoError(t, m.Close())
+
+	// 模拟崩溃时最后一条记录只写了一半
+	path := walPath(dir, gen)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Truncate(path, info.Size()-3))
+
+	m, err = Open[string, int](dir)
+	assert.NoError(t, err)
+	defer m.Close()
+	val, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+	_, ok = m.Get("b")
+	assert.False(t, ok)
+}
+
+func TestShardMapPersistEviction(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Open[int, int](dir, WithShardCount(1), WithCapacity(2))
+	assert.NoError(t, err)
+	m.Set(1, 1)
+	m.Set(2, 2)
+	m.Set(3, 3)
+	assert.NoError(t, m.Close())
+
+	m, err = Open[int, int](dir, WithShardCount(1), WithCapacity(2))
+	assert.NoError(t, err)
+	defer m.Close()
+	assert.Equal(t, 2, m.Len())
+	_, ok := m.Get(1)
+	assert.False(t, ok)
+}
+
+func TestShardMapPersistEvictionOrder(t *testing.T) {
+	testCase := []struct {
+		name    string
+		policy  func() EvictPolicy[int]
+		wantOut int
+	}{
+		// 快照按淘汰顺序 2、3、1 保存，恢复时容量变小，淘汰的总是 2
+		{name: "lru", policy: NewLRUPolicy[int], wantOut: 2},
+		// FIFO 不受访问影响，淘汰最早写入的 1
+		{name: "fifo", policy: NewFIFOPolicy[int], wantOut: 1},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			m, err := Open[int, int](dir, WithShardCount(1), WithCapacity(3), WithEvictPolicy(tc.policy))
+			assert.NoError(t, err)
+			m.Set(1, 1)
+			m.Set(2, 2)
+			m.Set(3, 3)
+			m.Get(1)
+			assert.NoError(t, m.Compact())
+			assert.NoError(t, m.Close())
+
+			for i := 0; i < 3; i++ {
+				m, err = Open[int, int](dir, WithShardCount(1), WithCapacity(2), WithEvictPolicy(tc.policy))
+				assert.NoError(t, err)
+				assert.Equal(t, 2, m.Len())
+				_, ok := m.Get(tc.wantOut)
+				assert.False(t, ok)
+				assert.NoError(t, m.Close())
+			}
+		})
+	}
+}
+
+func TestShardMapErr(t *testing.T) {
+	m, err := Open[string, int](t.TempDir(), WithSyncPolicy(SyncNever))
+	assert.NoError(t, err)
+	m.Set("a", 1)
+	assert.NoError(t, m.Err())
+
+	// 模拟写日志失败，内存中的写入不受影响
+	assert.NoError(t, m.wal.f.Close())
+	m.Set("b", 2)
+	assert.Error(t, m.Err())
+	assert.Equal(t, 2, m.Len())
+	// Err 不会清空错误
+	assert.Error(t, m.Err())
+	assert.Error(t, m.Sync())
+
+	m.Close()
+	assert.ErrorIs(t, m.Err(), ErrClosed)
+	assert.NoError(t, NewShardMap[string, int]().Err())
+}
+
+func TestShardMapNotPersistent(t *testing.T) {
+	m := NewShardMap[string, int]()
+	assert.NoError(t, m.Close())
+	assert.Error(t, m.Sync())
+	assert.Error(t, m.Compact())
+}
@@ -0,0 +1,380 @@
+package maps
+
+import (
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+// PersistentMap 不可变的持久化 map，基于 HAMT（hash array mapped trie）实现。
+// Set/Delete 不修改原 map，而是返回共享未修改部分的新版本，复杂度 O(log32 n)。
+// 任意版本都可以被多个 goroutine 同时读取，无需加锁；批量写入时使用 Transient 可以避免逐次复制节点
+type PersistentMap[K comparable, V any] struct {
+	root   *hamtNode[K, V]
+	size   int
+	hasher func(K) uint64
+}
+
+const (
+	hamtBits = 5
+	hamtMask = 1<<hamtBits - 1
+)
+
+// hamtNode 内部节点，bitmap 的第 i 位表示第 i 个分支存在，slots 按分支顺序紧凑存放
+type hamtNode[K comparable, V any] struct {
+	bitmap uint32
+	slots  []hamtSlot[K, V]
+	// owner 不为 nil 时节点属于某个 TransientMap，该 TransientMap 可以原地修改它
+	owner *hamtOwner
+}
+
+// hamtSlot 分支，node 与 leaf 只有一个不为 nil
+type hamtSlot[K comparable, V any] struct {
+	node *hamtNode[K, V]
+	leaf *hamtLeaf[K, V]
+}
+
+// hamtLeaf 叶子，哈希值完全相同的 key 存放在同一个叶子中
+type hamtLeaf[K comparable, V any] struct {
+	hash    uint64
+	entries []hamtEntry[K, V]
+}
+
+type hamtEntry[K comparable, V any] struct {
+	key K
+	val V
+}
+
+// hamtOwner 标识 TransientMap，不能是零大小类型，否则不同实例的指针可能相等
+type hamtOwner struct{ _ byte }
+
+// PersistentOption 用于配置 NewPersistentMap 创建的 PersistentMap
+type PersistentOption[K comparable] func(*persistentOptions[K])
+
+type persistentOptions[K comparable] struct {
+	hasher func(K) uint64
+}
+
+// WithPersistentHasher 自定义 key 的哈希函数，默认与 ShardMap 相同
+func WithPersistentHasher[K comparable](hasher func(K) uint64) PersistentOption[K] {
+	return func(o *persistentOptions[K]) {
+		o.hasher = hasher
+	}
+}
+
+// NewPersistentMap 创建空的 PersistentMap，与 ShardMap 使用相同的哈希函数，可通过 WithPersistentHasher 指定
+func NewPersistentMap[K comparable, V any](opts ...PersistentOption[K]) *PersistentMap[K, V] {
+	o := persistentOptions[K]{hasher: defaultHasher[K]}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &PersistentMap[K, V]{
+		root:   &hamtNode[K, V]{},
+		hasher: o.hasher,
+	}
+}
+
+// Get 获取 key 对应的值
+func (m *PersistentMap[K, V]) Get(key K) (V, bool) {
+	return m.root.get(m.hasher(key), key)
+}
+
+// Contains 是否包含 key
+func (m *PersistentMap[K, V]) Contains(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Len 返回元素个数
+func (m *PersistentMap[K, V]) Len() int {
+	return m.size
+}
+
+// Set 返回设置了 key 的新版本
+func (m *PersistentMap[K, V]) Set(key K, val V) *PersistentMap[K, V] {
+	root, added := m.root.set(0, m.hasher(key), key, val, nil)
+	res := &PersistentMap[K, V]{root: root, size: m.size, hasher: m.hasher}
+	if added {
+		res.size++
+	}
+	return res
+}
+
+// Delete 返回删除了 key 的新版本，key 不存在时返回 m 本身
+func (m *PersistentMap[K, V]) Delete(key K) *PersistentMap[K, V] {
+	root, removed := m.root.delete(0, m.hasher(key), key, nil)
+	if !removed {
+		return m
+	}
+	return &PersistentMap[K, V]{root: root, size: m.size - 1, hasher: m.hasher}
+}
+
+// Keys 返回所有 key，顺序由哈希值决定
+func (m *PersistentMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.size)
+	for k := range m.KeysSeq() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values 返回所有值
+func (m *PersistentMap[K, V]) Values() []V {
+	values := make([]V, 0, m.size)
+	for v := range m.ValuesSeq() {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Range 遍历所有键值, f返回true则停止
+func (m *PersistentMap[K, V]) Range(f func(key K, value V) (stop bool)) {
+	for k, v := range m.All() {
+		if f(k, v) {
+			return
+		}
+	}
+}
+
+// All 返回遍历所有键值的迭代器
+func (m *PersistentMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.root.walk(yield)
+	}
+}
+
+// KeysSeq 返回遍历所有 key 的迭代器
+func (m *PersistentMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		m.root.walk(func(k K, _ V) bool { return yield(k) })
+	}
+}
+
+// ValuesSeq 返回遍历所有值的迭代器
+func (m *PersistentMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		m.root.walk(func(_ K, v V) bool { return yield(v) })
+	}
+}
+
+// Transient 返回基于当前版本的 TransientMap，用于批量写入，不影响 m
+func (m *PersistentMap[K, V]) Transient() *TransientMap[K, V] {
+	return &TransientMap[K, V]{
+		root:   m.root,
+		size:   m.size,
+		hasher: m.hasher,
+		owner:  &hamtOwner{},
+	}
+}
+
+// TransientMap PersistentMap 的可变构建器，只会原地修改自己创建的节点，与 PersistentMap 共享的节点仍然先复制再修改。
+// 不是并发安全的！！！
+type TransientMap[K comparable, V any] struct {
+	root   *hamtNode[K, V]
+	size   int
+	hasher func(K) uint64
+	owner  *hamtOwner
+}
+
+// Get 获取 key 对应的值
+func (t *TransientMap[K, V]) Get(key K) (V, bool) {
+	return t.root.get(t.hasher(key), key)
+}
+
+// Len 返回元素个数
+func (t *TransientMap[K, V]) Len() int {
+	return t.size
+}
+
+// Set 设置 key
+func (t *TransientMap[K, V]) Set(key K, val V) {
+	root, added := t.root.set(0, t.hasher(key), key, val, t.owner)
+	t.root = root
+	if added {
+		t.size++
+	}
+}
+
+// Delete 删除 key，返回 key 是否存在
+func (t *TransientMap[K, V]) Delete(key K) bool {
+	root, removed := t.root.delete(0, t.hasher(key), key, t.owner)
+	if removed {
+		t.root = root
+		t.size--
+	}
+	return removed
+}
+
+// Persistent 返回当前内容的 PersistentMap。之后 TransientMap 仍可继续使用，
+// 但会换一个新的 owner，不会再修改已经返回的版本
+func (t *TransientMap[K, V]) Persistent() *PersistentMap[K, V] {
+	t.owner = &hamtOwner{}
+	return &PersistentMap[K, V]{root: t.root, size: t.size, hasher: t.hasher}
+}
+
+// index 返回 hash 在 shift 层对应的分支位以及它在 slots 中的下标
+func (n *hamtNode[K, V]) index(shift uint, hash uint64) (uint32, int) {
+	bit := uint32(1) << ((hash >> shift) & hamtMask)
+	return bit, bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+// editable 返回可以原地修改的节点：属于 owner 时返回自身，否则返回副本
+func (n *hamtNode[K, V]) editable(owner *hamtOwner) *hamtNode[K, V] {
+	if owner != nil && n.owner == owner {
+		return n
+	}
+	return &hamtNode[K, V]{bitmap: n.bitmap, slots: slices.Clone(n.slots), owner: owner}
+}
+
+func (n *hamtNode[K, V]) get(hash uint64, key K) (V, bool) {
+	for shift := uint(0); ; shift += hamtBits {
+		bit, idx := n.index(shift, hash)
+		if n.bitmap&bit == 0 {
+			break
+		}
+		s := n.slots[idx]
+		if s.node != nil {
+			n = s.node
+			continue
+		}
+		if s.leaf.hash == hash {
+			for _, e := range s.leaf.entries {
+				if e.key == key {
+					return e.val, true
+				}
+			}
+		}
+		break
+	}
+	var zero V
+	return zero, false
+}
+
+// set 返回设置 key 之后的节点，以及 key 是否是新增的
+func (n *hamtNode[K, V]) set(shift uint, hash uint64, key K, val V, owner *hamtOwner) (*hamtNode[K, V], bool) {
+	bit, idx := n.index(shift, hash)
+	if n.bitmap&bit == 0 {
+		nn := n.editable(owner)
+		nn.bitmap |= bit
+		leaf := &hamtLeaf[K, V]{hash: hash, entries: []hamtEntry[K, V]{{key: key, val: val}}}
+		nn.slots = slices.Insert(nn.slots, idx, hamtSlot[K, V]{leaf: leaf})
+		return nn, true
+	}
+
+	var (
+		slot  hamtSlot[K, V]
+		added bool
+	)
+	s := n.slots[idx]
+	switch {
+	case s.node != nil:
+		var child *hamtNode[K, V]
+		child, added = s.node.set(shift+hamtBits, hash, key, val, owner)
+		if child == s.node {
+			return n, added
+		}
+		slot.node = child
+	case s.leaf.hash == hash:
+		i := slices.IndexFunc(s.leaf.entries, func(e hamtEntry[K, V]) bool { return e.key == key })
+		entries := slices.Clone(s.leaf.entries)
+		if i >= 0 {
+			entries[i].val = val
+		} else {
+			entries = append(entries, hamtEntry[K, V]{key: key, val: val})
+			added = true
+		}
+		slot.leaf = &hamtLeaf[K, V]{hash: hash, entries: entries}
+	default:
+		// 哈希值不同，向下分裂直到两者落在不同的分支
+		leaf := &hamtLeaf[K, V]{hash: hash, entries: []hamtEntry[K, V]{{key: key, val: val}}}
+		slot.node = splitLeaves(shift+hamtBits, s.leaf, leaf, owner)
+		added = true
+	}
+	nn := n.editable(owner)
+	nn.slots[idx] = slot
+	return nn, added
+}
+
+// splitLeaves 创建包含两个哈希值不同的叶子的节点，64 位哈希值不同时最终一定会分开
+func splitLeaves[K comparable, V any](shift uint, a, b *hamtLeaf[K, V], owner *hamtOwner) *hamtNode[K, V] {
+	ia, ib := (a.hash>>shift)&hamtMask, (b.hash>>shift)&hamtMask
+	n := &hamtNode[K, V]{bitmap: 1<<ia | 1<<ib, owner: owner}
+	switch {
+	case ia == ib:
+		n.slots = []hamtSlot[K, V]{{node: splitLeaves(shift+hamtBits, a, b, owner)}}
+	case ia < ib:
+		n.slots = []hamtSlot[K, V]{{leaf: a}, {leaf: b}}
+	default:
+		n.slots = []hamtSlot[K, V]{{leaf: b}, {leaf: a}}
+	}
+	return n
+}
+
+// delete 返回删除 key 之后的节点，以及 key 是否存在。
+// 子节点只剩一个叶子时会把叶子上提，保持树的形状只与内容有关
+func (n *hamtNode[K, V]) delete(shift uint, hash uint64, key K, owner *hamtOwner) (*hamtNode[K, V], bool) {
+	bit, idx := n.index(shift, hash)
+	if n.bitmap&bit == 0 {
+		return n, false
+	}
+
+	var slot hamtSlot[K, V]
+	s := n.slots[idx]
+	if s.node != nil {
+		child, removed := s.node.delete(shift+hamtBits, hash, key, owner)
+		if !removed {
+			return n, false
+		}
+		switch {
+		case len(child.slots) == 0:
+			return n.removeSlot(bit, idx, owner), true
+		case len(child.slots) == 1 && child.slots[0].leaf != nil:
+			slot.leaf = child.slots[0].leaf
+		case child == s.node:
+			return n, true
+		default:
+			slot.node = child
+		}
+	} else {
+		if s.leaf.hash != hash {
+			return n, false
+		}
+		i := slices.IndexFunc(s.leaf.entries, func(e hamtEntry[K, V]) bool { return e.key == key })
+		if i < 0 {
+			return n, false
+		}
+		if len(s.leaf.entries) == 1 {
+			return n.removeSlot(bit, idx, owner), true
+		}
+		entries := slices.Delete(slices.Clone(s.leaf.entries), i, i+1)
+		slot.leaf = &hamtLeaf[K, V]{hash: hash, entries: entries}
+	}
+	nn := n.editable(owner)
+	nn.slots[idx] = slot
+	return nn, true
+}
+
+func (n *hamtNode[K, V]) removeSlot(bit uint32, idx int, owner *hamtOwner) *hamtNode[K, V] {
+	nn := n.editable(owner)
+	nn.bitmap &^= bit
+	nn.slots = slices.Delete(nn.slots, idx, idx+1)
+	return nn
+}
+
+// walk 深度优先遍历，yield 返回 false 时停止并返回 false
+func (n *hamtNode[K, V]) walk(yield func(K, V) bool) bool {
+	for _, s := range n.slots {
+		if s.node != nil {
+			if !s.node.walk(yield) {
+				return false
+			}
+			continue
+		}
+		for _, e := range s.leaf.entries {
+			if !yield(e.key, e.val) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,229 @@
+package maps
+
+import (
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentMap(t *testing.T) {
+	m0 := NewPersistentMap[string, int]()
+	m1 := m0.Set("a", 1)
+	m2 := m1.Set("b", 2)
+	m3 := m2.Set("a", 10)
+	m4 := m3.Delete("b")
+
+	testCase := []struct {
+		name string
+		m    *PersistentMap[string, int]
+		want map[string]int
+	}{
+		{name: "empty", m: m0, want: map[string]int{}},
+		{name: "set a", m: m1, want: map[string]int{"a": 1}},
+		{name: "set b", m: m2, want: map[string]int{"a": 1, "b": 2}},
+		{name: "overwrite a", m: m3, want: map[string]int{"a": 10, "b": 2}},
+		{name: "delete b", m: m4, want: map[string]int{"a": 10}},
+	}
+	// 旧版本不受新版本影响
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, len(tc.want), tc.m.Len())
+			got := make(map[string]int)
+			for k, v := range tc.m.All() {
+				got[k] = v
+			}
+			assert.Equal(t, tc.want, got)
+			for k, v := range tc.want {
+				val, ok := tc.m.Get(k)
+				assert.True(t, ok)
+				assert.Equal(t, v, val)
+			}
+		})
+	}
+
+	assert.Same(t, m4, m4.Delete("missing"))
+	assert.False(t, m4.Contains("b"))
+}
+
+func TestPersistentMapRandom(t *testing.T) {
+	testCase := []struct {
+		name   string
+		hasher func(int) uint64
+	}{
+		{name: "default"},
+		// 大量哈希冲突
+		{name: "collision", hasher: func(k int) uint64 { return uint64(k % 7) }},
+		// 低位相同，需要分裂到很深的层级
+		{name: "deep", hasher: func(k int) uint64 { return uint64(k) << 50 }},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			var opts []PersistentOption[int]
+			if tc.hasher != nil {
+				opts = append(opts, WithPersistentHasher(tc.hasher))
+			}
+			m := NewPersistentMap[int, int](opts...)
+			want := make(map[int]int)
+			for i := 0; i < 5000; i++ {
+				k := rand.IntN(300)
+				if rand.IntN(3) == 0 {
+					m = m.Delete(k)
+					delete(want, k)
+				} else {
+					m = m.Set(k, i)
+					want[k] = i
+				}
+			}
+			assertPersistentMap(t, want, m)
+
+			for k := range want {
+				m = m.Delete(k)
+			}
+			assert.Equal(t, 0, m.Len())
+			assert.Empty(t, m.root.slots)
+		})
+	}
+}
+
+func TestPersistentMapTransient(t *testing.T) {
+	base := NewPersistentMap[int, string]().Set(-1, "base")
+
+	tr := base.Transient()
+	want := map[int]string{-1: "base"}
+	for i := 0; i < 1000; i++ {
+		tr.Set(i, strconv.Itoa(i))
+		want[i] = strconv.Itoa(i)
+	}
+	assert.True(t, tr.Delete(-1))
+	assert.False(t, tr.Delete(-1))
+	delete(want, -1)
+	assert.Equal(t, 1000, tr.Len())
+
+	m := tr.Persistent()
+	assertPersistentMap(t, want, m)
+	// 基础版本不变
+	assert.Equal(t, 1, base.Len())
+	v, _ := base.Get(-1)
+	assert.Equal(t, "base", v)
+
+	// 之后继续修改 TransientMap 不会影响已经返回的版本
+	for i := 0; i < 1000; i += 2 {
+		tr.Delete(i)
+	}
+	tr.Set(5000, "x")
+	assertPersistentMap(t, want, m)
+	assert.Equal(t, 501, tr.Len())
+	v, ok := tr.Get(5000)
+	assert.True(t, ok)
+	assert.Equal(t, "x", v)
+}
+
+func TestPersistentMapConcurrentRead(t *testing.T) {
+	m := NewPersistentMap[int, int]()
+	for i := 0; i < 1000; i++ {
+		m = m.Set(i, i)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				v, ok := m.Get(i)
+				assert.True(t, ok)
+				assert.Equal(t, i, v)
+			}
+			assert.Equal(t, 1000, len(m.Keys()))
+		}()
+	}
+	// 读取的同时基于同一版本写出新版本
+	next := m
+	for i := 0; i < 1000; i++ {
+		next = next.Set(i, -i)
+	}
+	wg.Wait()
+	v, _ := next.Get(10)
+	assert.Equal(t, -10, v)
+}
+
+func TestPersistentMapRange(t *testing.T) {
+	m := NewPersistentMap[int, int]()
+	for i := 0; i < 100; i++ {
+		m = m.Set(i, i*2)
+	}
+	keys, values := m.Keys(), m.Values()
+	sort.Ints(keys)
+	sort.Ints(values)
+	assert.Equal(t, 100, len(keys))
+	assert.Equal(t, 99, keys[99])
+	assert.Equal(t, 198, values[99])
+
+	n := 0
+	m.Range(func(key, value int) bool {
+		n++
+		return n == 10
+	})
+	assert.Equal(t, 10, n)
+}
+
+// assertPersistentMap 比对内容，并检查除根节点外不存在只包含一个叶子的节点
+func assertPersistentMap[K comparable, V any](t *testing.T, want map[K]V, m *PersistentMap[K, V]) {
+	t.Helper()
+	assert.Equal(t, len(want), m.Len())
+	got := make(map[K]V, m.Len())
+	for k, v := range m.All() {
+		got[k] = v
+	}
+	assert.Equal(t, want, got)
+	for k, v := range want {
+		val, ok := m.Get(k)
+		assert.True(t, ok)
+		assert.Equal(t, v, val)
+	}
+
+	var check func(n *hamtNode[K, V], root bool)
+	check = func(n *hamtNode[K, V], root bool) {
+		if !root && len(n.slots) == 1 && n.slots[0].leaf != nil {
+			t.Errorf("node with a single leaf")
+		}
+		for _, s := range n.slots {
+			if s.node != nil {
+				check(s.node, false)
+			}
+		}
+	}
+	check(m.root, true)
+}
+
+func BenchmarkPersistentMap(b *testing.B) {
+	b.Run("Set", func(b *testing.B) {
+		m := NewPersistentMap[int, int]()
+		for i := 0; i < b.N; i++ {
+			m = m.Set(i, i)
+		}
+	})
+	b.Run("Transient", func(b *testing.B) {
+		t := NewPersistentMap[int, int]().Transient()
+		for i := 0; i < b.N; i++ {
+			t.Set(i, i)
+		}
+		_ = t.Persistent()
+	})
+	b.Run("Get", func(b *testing.B) {
+		t := NewPersistentMap[int, int]().Transient()
+		for i := 0; i < 100000; i++ {
+			t.Set(i, i)
+		}
+		m := t.Persistent()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m.Get(i % 100000)
+		}
+	})
+}
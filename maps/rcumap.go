@@ -0,0 +1,529 @@
+package maps
+
+import (
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RCUMap 读多写少场景下的并发 map，每个桶是一个不可变的数组，通过 atomic.Pointer 发布（copy-on-write）。
+// 读操作不加锁也不写共享内存，不会像 ShardMap 的 RWMutex 那样让读者争抢同一个缓存行；
+// 写操作复制整个桶后 CAS 替换，冲突时重试。扩容期间写入正在迁移的桶会等待扩容完成。
+// 与 ShardMap 的 API 相同，包括 TTL、批量操作、Snapshot/Clone 与序列化，但不支持：
+//   - 容量限制与淘汰：淘汰顺序需要跨桶共享的可变状态，每次读取都要更新；
+//   - Watch 与 Stats：需要在每次读写时修改共享的计数或订阅表，抵消了读操作不写共享内存的优势；
+//   - 持久化与 Txn：依赖分片锁保证日志顺序与版本检查；
+//   - Resize：桶数随元素个数自动扩容
+type RCUMap[K comparable, V any] struct {
+	table  atomic.Pointer[rcuTable[K, V]]
+	size   atomic.Int64
+	hasher func(K) uint64
+	// resizeMu 同一时刻只有一个扩容、Clear 或需要冻结所有桶的操作
+	resizeMu sync.Mutex
+
+	janitorMu sync.Mutex
+	janitor   *janitor
+}
+
+const (
+	defaultRCUBuckets = 64
+	// rcuMaxLoad 平均每个桶的元素个数超过该值时扩容一倍
+	rcuMaxLoad = 2
+)
+
+type rcuTable[K comparable, V any] struct {
+	buckets []atomic.Pointer[rcuBucket[K, V]]
+	mask    uint64
+}
+
+// rcuBucket 发布后不再修改，frozen 表示正在迁移到新表，其内容就是迁移时的最终内容
+type rcuBucket[K comparable, V any] struct {
+	entries []rcuEntry[K, V]
+	frozen  bool
+}
+
+// rcuEntry expireAt 为 0 表示永不过期
+type rcuEntry[K comparable, V any] struct {
+	hash     uint64
+	key      K
+	val      V
+	expireAt int64
+}
+
+// rcuOp mutate 回调决定的操作
+type rcuOp int
+
+const (
+	rcuKeep rcuOp = iota
+	rcuSet
+	rcuDelete
+)
+
+// keepTTL 传给 mutate 表示保留 key 原有的过期时间
+const keepTTL = -1
+
+// RCUOption 用于配置 NewRCUMap 创建的 RCUMap
+type RCUOption[K comparable] func(*rcuOptions[K])
+
+type rcuOptions[K comparable] struct {
+	hasher func(K) uint64
+}
+
+// WithRCUHasher 自定义 key 的哈希函数，用于决定 key 落在哪个桶，默认与 ShardMap 相同
+func WithRCUHasher[K comparable](hasher func(K) uint64) RCUOption[K] {
+	return func(o *rcuOptions[K]) {
+		o.hasher = hasher
+	}
+}
+
+// NewRCUMap 创建 RCUMap，与 ShardMap 使用相同的哈希函数，可通过 WithRCUHasher 指定
+func NewRCUMap[K comparable, V any](opts ...RCUOption[K]) *RCUMap[K, V] {
+	o := rcuOptions[K]{hasher: defaultHasher[K]}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	m := &RCUMap[K, V]{hasher: o.hasher}
+	m.table.Store(newRCUTable[K, V](defaultRCUBuckets))
+	return m
+}
+
+func newRCUTable[K comparable, V any](n uint64) *rcuTable[K, V] {
+	return &rcuTable[K, V]{
+		buckets: make([]atomic.Pointer[rcuBucket[K, V]], n),
+		mask:    n - 1,
+	}
+}
+
+func (m *RCUMap[K, V]) Get(key K) (V, bool) {
+	val, _, ok := m.lookup(key)
+	return val, ok
+}
+
+// Set 写入 key，会清除 key 原有的过期时间
+func (m *RCUMap[K, V]) Set(key K, val V) {
+	m.set(key, val, 0)
+}
+
+func (m *RCUMap[K, V]) Delete(key K) {
+	m.mutate(key, keepTTL, func(old V, _ bool) (V, rcuOp) {
+		return old, rcuDelete
+	})
+}
+
+// Len 返回元素个数，包含已过期但尚未清理的元素，需要精确值时使用 ExactLen
+func (m *RCUMap[K, V]) Len() int {
+	return int(m.size.Load())
+}
+
+// SetWithTTL 设置 key, value，并在 ttl 之后过期；ttl <= 0 时等同于 Set
+func (m *RCUMap[K, V]) SetWithTTL(key K, val V, ttl time.Duration) {
+	var expireAt int64
+	if ttl > 0 {
+		expireAt = time.Now().Add(ttl).UnixNano()
+	}
+	m.set(key, val, expireAt)
+}
+
+// GetWithTTL 获取值以及剩余存活时间，未设置过期时间的 key 剩余时间为 0
+func (m *RCUMap[K, V]) GetWithTTL(key K) (V, time.Duration, bool) {
+	val, expireAt, ok := m.lookup(key)
+	if !ok || expireAt == 0 {
+		return val, 0, ok
+	}
+	return val, time.Duration(expireAt - time.Now().UnixNano()), true
+}
+
+// StartJanitor 启动一个后台清理协程，每隔 interval 清理一次所有桶中已过期的 key。
+// 重复调用会先停止之前的清理协程
+func (m *RCUMap[K, V]) StartJanitor(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	m.janitorMu.Lock()
+	defer m.janitorMu.Unlock()
+
+	if m.janitor != nil {
+		m.janitor.stopAndWait()
+	}
+	j := &janitor{interval: interval, stop: make(chan struct{})}
+	j.wg.Add(1)
+	go j.run(interval, 0, m.sweep)
+	m.janitor = j
+}
+
+// StopJanitor 停止后台清理协程，并等待其退出
+func (m *RCUMap[K, V]) StopJanitor() {
+	m.janitorMu.Lock()
+	defer m.janitorMu.Unlock()
+
+	if m.janitor != nil {
+		m.janitor.stopAndWait()
+		m.janitor = nil
+	}
+}
+
+// Clear 删除所有元素，并将桶数恢复为初始值
+func (m *RCUMap[K, V]) Clear() {
+	m.resizeMu.Lock()
+	defer m.resizeMu.Unlock()
+
+	old := m.table.Load()
+	var n int64
+	for _, b := range m.freeze(old) {
+		n += int64(len(b.entries))
+	}
+	m.table.Store(newRCUTable[K, V](defaultRCUBuckets))
+	m.size.Add(-n)
+}
+
+func (m *RCUMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.Len())
+	for k := range m.KeysSeq() {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (m *RCUMap[K, V]) Values() []V {
+	values := make([]V, 0, m.Len())
+	for v := range m.ValuesSeq() {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Range 遍历所有键值, f返回true则停止
+func (m *RCUMap[K, V]) Range(f func(key K, value V) (stop bool)) {
+	for k, v := range m.All() {
+		if f(k, v) {
+			return
+		}
+	}
+}
+
+// All 返回遍历所有未过期键值的迭代器，不加锁，每个桶读取的是遍历到它时的内容，
+// 与 sync.Map.Range 一样不保证是某一时刻的快照，需要一致的视图时使用 Snapshot
+func (m *RCUMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := range m.entries() {
+			if !yield(e.key, e.val) {
+				return
+			}
+		}
+	}
+}
+
+func (m *RCUMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+func (m *RCUMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// GetOrSet key 存在时返回已有的值，否则写入 val
+func (m *RCUMap[K, V]) GetOrSet(key K, val V) (actual V, loaded bool) {
+	if v, ok := m.Get(key); ok {
+		return v, true
+	}
+	old, ok, _ := m.mutate(key, 0, func(old V, ok bool) (V, rcuOp) {
+		if ok {
+			return old, rcuKeep
+		}
+		return val, rcuSet
+	})
+	if ok {
+		return old, true
+	}
+	return val, false
+}
+
+// LoadAndDelete 删除 key 并返回删除前的值
+func (m *RCUMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	old, ok, _ := m.mutate(key, keepTTL, func(old V, _ bool) (V, rcuOp) {
+		return old, rcuDelete
+	})
+	return old, ok
+}
+
+// CompareAndSwapFunc 当前值与 old 按 eq 比较相等时替换为 new，保留 key 原有的过期时间。
+// 并发写入同一个桶时 eq 可能被调用多次
+func (m *RCUMap[K, V]) CompareAndSwapFunc(key K, old, new V, eq func(a, b V) bool) (swapped bool) {
+	m.mutate(key, keepTTL, func(cur V, ok bool) (V, rcuOp) {
+		swapped = ok && eq(cur, old)
+		if !swapped {
+			return cur, rcuKeep
+		}
+		return new, rcuSet
+	})
+	return swapped
+}
+
+// CompareAndDeleteFunc 当前值与 old 按 eq 比较相等时删除 key，并发写入同一个桶时 eq 可能被调用多次
+func (m *RCUMap[K, V]) CompareAndDeleteFunc(key K, old V, eq func(a, b V) bool) (deleted bool) {
+	m.mutate(key, keepTTL, func(cur V, ok bool) (V, rcuOp) {
+		deleted = ok && eq(cur, old)
+		if !deleted {
+			return cur, rcuKeep
+		}
+		return cur, rcuDelete
+	})
+	return deleted
+}
+
+// Compute 根据旧值计算新值：fn 返回 keep 为 false 时删除 key，否则写入 newVal。
+// 已存在的 key 保留原有的过期时间。返回计算后的值以及 key 是否存在。
+// 与 ShardMap 不同，fn 不在锁内执行，并发写入同一个桶时可能被调用多次，只有最后一次的结果生效
+func (m *RCUMap[K, V]) Compute(key K, fn func(old V, ok bool) (newVal V, keep bool)) (V, bool) {
+	var keep bool
+	_, _, res := m.mutate(key, keepTTL, func(old V, ok bool) (V, rcuOp) {
+		var newVal V
+		newVal, keep = fn(old, ok)
+		if !keep {
+			return newVal, rcuDelete
+		}
+		return newVal, rcuSet
+	})
+	if !keep {
+		var zero V
+		return zero, false
+	}
+	return res, true
+}
+
+// Upsert 插入或更新：fn 根据 key 是否存在、旧值和传入的 val 计算最终写入的值并返回。
+// 已存在的 key 保留原有的过期时间。与 Compute 一样，fn 可能被调用多次
+func (m *RCUMap[K, V]) Upsert(key K, val V, fn func(exist bool, old V, new V) V) V {
+	_, _, res := m.mutate(key, keepTTL, func(old V, ok bool) (V, rcuOp) {
+		return fn(ok, old, val), rcuSet
+	})
+	return res
+}
+
+// set 写入 key，expireAt 为 0 表示永不过期
+func (m *RCUMap[K, V]) set(key K, val V, expireAt int64) {
+	m.mutate(key, expireAt, func(V, bool) (V, rcuOp) {
+		return val, rcuSet
+	})
+}
+
+// lookup 读取未过期的值及其过期时间，遇到已过期的 key 时将其删除
+func (m *RCUMap[K, V]) lookup(key K) (V, int64, bool) {
+	h := m.hasher(key)
+	t := m.table.Load()
+	b := t.buckets[h&t.mask].Load()
+	if i := b.find(h, key); i >= 0 {
+		e := &b.entries[i]
+		if e.alive() {
+			return e.val, e.expireAt, true
+		}
+		m.expireKey(key)
+	}
+	var zero V
+	return zero, 0, false
+}
+
+// expireKey 删除已过期的 key，期间被重新写入的 key 不受影响
+func (m *RCUMap[K, V]) expireKey(key K) {
+	m.mutate(key, keepTTL, func(old V, ok bool) (V, rcuOp) {
+		if ok {
+			return old, rcuKeep
+		}
+		return old, rcuDelete
+	})
+}
+
+// sweep 清理所有桶中已过期的 key，正在扩容的桶留到下一次清理
+func (m *RCUMap[K, V]) sweep() {
+	now := time.Now().UnixNano()
+	expired := func(e rcuEntry[K, V]) bool {
+		return e.expired(now)
+	}
+	t := m.table.Load()
+	for i := range t.buckets {
+		for {
+			b := t.buckets[i].Load()
+			if b == nil || b.frozen || !slices.ContainsFunc(b.entries, expired) {
+				break
+			}
+			var nb *rcuBucket[K, V]
+			if entries := slices.DeleteFunc(slices.Clone(b.entries), expired); len(entries) > 0 {
+				nb = &rcuBucket[K, V]{entries: entries}
+			}
+			if t.buckets[i].CompareAndSwap(b, nb) {
+				m.size.Add(int64(len(nb.items()) - len(b.entries)))
+				break
+			}
+		}
+	}
+}
+
+// entries 遍历所有未过期的元素，供 All 与序列化使用，零值的 RCUMap 不包含任何元素
+func (m *RCUMap[K, V]) entries() iter.Seq[rcuEntry[K, V]] {
+	return func(yield func(rcuEntry[K, V]) bool) {
+		t := m.table.Load()
+		if t == nil {
+			return
+		}
+		now := time.Now().UnixNano()
+		for i := range t.buckets {
+			for _, e := range t.buckets[i].Load().items() {
+				if !e.expired(now) && !yield(e) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// mutate 读取 key 的当前值交给 fn 决定如何修改，然后复制桶并 CAS 替换，失败时重试。
+// 已过期的 key 对 fn 来说不存在。写入时 expireAt 为新的过期时间，为 keepTTL 时保留未过期 key 原有的过期时间。
+// 返回修改前的值、修改前 key 是否存在以及 fn 最后一次返回的值
+func (m *RCUMap[K, V]) mutate(key K, expireAt int64, fn func(old V, ok bool) (V, rcuOp)) (V, bool, V) {
+	h := m.hasher(key)
+	for {
+		t := m.table.Load()
+		slot := &t.buckets[h&t.mask]
+		b := slot.Load()
+		if b != nil && b.frozen {
+			// 扩容持有 resizeMu 直到新表发布
+			m.resizeMu.Lock()
+			m.resizeMu.Unlock()
+			continue
+		}
+
+		var old V
+		// i >= 0 而 ok 为 false 表示 key 已过期但尚未清理，写入时原地覆盖，删除时一并清理
+		i := b.find(h, key)
+		ok := i >= 0 && b.entries[i].alive()
+		if ok {
+			old = b.entries[i].val
+		}
+		val, op := fn(old, ok)
+		exp := expireAt
+		if exp == keepTTL {
+			exp = 0
+			if ok {
+				exp = b.entries[i].expireAt
+			}
+		}
+
+		var nb *rcuBucket[K, V]
+		switch {
+		case op == rcuSet && i >= 0:
+			nb = &rcuBucket[K, V]{entries: slices.Clone(b.entries)}
+			nb.entries[i].val = val
+			nb.entries[i].expireAt = exp
+		case op == rcuSet:
+			nb = &rcuBucket[K, V]{entries: append(slices.Clip(b.items()), rcuEntry[K, V]{hash: h, key: key, val: val, expireAt: exp})}
+		case op == rcuDelete && i >= 0:
+			if len(b.entries) > 1 {
+				nb = &rcuBucket[K, V]{entries: slices.Delete(slices.Clone(b.entries), i, i+1)}
+			}
+		default:
+			return old, ok, val
+		}
+		if !slot.CompareAndSwap(b, nb) {
+			continue
+		}
+
+		switch {
+		case op == rcuSet && i < 0:
+			if n := m.size.Add(1); uint64(n) > uint64(len(t.buckets))*rcuMaxLoad {
+				m.grow(t)
+			}
+		case op == rcuDelete:
+			m.size.Add(-1)
+		}
+		return old, ok, val
+	}
+}
+
+// grow 将桶数扩大一倍，已有扩容在进行或表已经变化时直接返回
+func (m *RCUMap[K, V]) grow(t *rcuTable[K, V]) {
+	if !m.resizeMu.TryLock() {
+		return
+	}
+	defer m.resizeMu.Unlock()
+	if m.table.Load() != t {
+		return
+	}
+
+	nt := newRCUTable[K, V](uint64(len(t.buckets)) * 2)
+	entries := make([][]rcuEntry[K, V], len(nt.buckets))
+	for _, b := range m.freeze(t) {
+		for _, e := range b.entries {
+			idx := e.hash & nt.mask
+			entries[idx] = append(entries[idx], e)
+		}
+	}
+	for i, es := range entries {
+		if len(es) > 0 {
+			nt.buckets[i].Store(&rcuBucket[K, V]{entries: es})
+		}
+	}
+	m.table.Store(nt)
+}
+
+// freeze 将 t 的所有桶替换为 frozen 的副本并返回，之后写入这些桶的操作会等待新表发布。调用方需持有 resizeMu
+func (m *RCUMap[K, V]) freeze(t *rcuTable[K, V]) []*rcuBucket[K, V] {
+	frozen := make([]*rcuBucket[K, V], len(t.buckets))
+	for i := range t.buckets {
+		for {
+			b := t.buckets[i].Load()
+			fb := &rcuBucket[K, V]{frozen: true}
+			if b != nil {
+				fb.entries = b.entries
+			}
+			if t.buckets[i].CompareAndSwap(b, fb) {
+				frozen[i] = fb
+				break
+			}
+		}
+	}
+	return frozen
+}
+
+// items 返回桶中的元素，b 可以为 nil
+func (b *rcuBucket[K, V]) items() []rcuEntry[K, V] {
+	if b == nil {
+		return nil
+	}
+	return b.entries
+}
+
+// alive 是否未过期，只有设置了过期时间时才读取当前时间
+func (e *rcuEntry[K, V]) alive() bool {
+	return e.expireAt == 0 || time.Now().UnixNano() < e.expireAt
+}
+
+func (e *rcuEntry[K, V]) expired(now int64) bool {
+	return e.expireAt > 0 && now >= e.expireAt
+}
+
+// find 返回 key 在桶中的下标，不存在时返回 -1，b 可以为 nil
+func (b *rcuBucket[K, V]) find(hash uint64, key K) int {
+	if b == nil {
+		return -1
+	}
+	for i := range b.entries {
+		if b.entries[i].hash == hash && b.entries[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
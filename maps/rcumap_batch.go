@@ -0,0 +1,60 @@
+package maps
+
+// RCUMap 的写入不加锁，批量操作逐个 key 执行即可，没有 ShardMap 按分片分组加锁的收益；
+// 提供这些方法是为了与 ShardMap 的 API 保持一致
+
+// SetMany 批量写入，不保证原子性，读方可能看到部分写入；需要原子性时使用 SetManyAtomic
+func (m *RCUMap[K, V]) SetMany(items map[K]V) {
+	for k, v := range items {
+		m.Set(k, v)
+	}
+}
+
+// GetMany 批量读取，返回存在且未过期的键值对
+func (m *RCUMap[K, V]) GetMany(keys []K) map[K]V {
+	res := make(map[K]V, len(keys))
+	for _, k := range keys {
+		if v, ok := m.Get(k); ok {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// DeleteMany 批量删除，不保证原子性
+func (m *RCUMap[K, V]) DeleteMany(keys []K) {
+	for _, k := range keys {
+		m.Delete(k)
+	}
+}
+
+// SetManyAtomic 原子地批量写入：冻结所有桶，在新表中写入后一次性发布，
+// 读方要么看不到任何写入，要么看到全部写入。代价与 Snapshot 相同
+func (m *RCUMap[K, V]) SetManyAtomic(items map[K]V) {
+	var added int64
+	var t *rcuTable[K, V]
+	m.exclusive(func(nt *rcuTable[K, V]) {
+		t = nt
+		for k, v := range items {
+			if nt.put(rcuEntry[K, V]{hash: m.hasher(k), key: k, val: v}) {
+				added++
+			}
+		}
+	})
+	if n := m.size.Add(added); uint64(n) > uint64(len(t.buckets))*rcuMaxLoad {
+		m.grow(t)
+	}
+}
+
+// DeleteManyAtomic 原子地批量删除，方式同 SetManyAtomic
+func (m *RCUMap[K, V]) DeleteManyAtomic(keys []K) {
+	var removed int64
+	m.exclusive(func(t *rcuTable[K, V]) {
+		for _, k := range keys {
+			if t.remove(m.hasher(k), k) {
+				removed++
+			}
+		}
+	})
+	m.size.Add(-removed)
+}
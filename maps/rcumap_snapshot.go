@@ -0,0 +1,125 @@
+package maps
+
+import (
+	"iter"
+	"slices"
+	"time"
+)
+
+// Snapshot 冻结所有桶后复制全部未过期的数据，返回跨桶一致的只读视图。
+// 冻结期间写入会等待，读取不受影响；需要为每个桶分配新的指针，数据量较大时应避免频繁调用
+func (m *RCUMap[K, V]) Snapshot() *Snapshot[K, V] {
+	items := make(map[K]V, m.Len())
+	m.exclusive(func(t *rcuTable[K, V]) {
+		now := time.Now().UnixNano()
+		for e := range t.all() {
+			if !e.expired(now) {
+				items[e.key] = e.val
+			}
+		}
+	})
+	return &Snapshot[K, V]{items: items}
+}
+
+// Clone 返回使用相同哈希函数的新 RCUMap，包含当前所有数据及其 TTL，不会继承 janitor
+func (m *RCUMap[K, V]) Clone() *RCUMap[K, V] {
+	var buf []rcuEntry[K, V]
+	m.exclusive(func(t *rcuTable[K, V]) {
+		now := time.Now().UnixNano()
+		buf = make([]rcuEntry[K, V], 0, m.Len())
+		for e := range t.all() {
+			if !e.expired(now) {
+				buf = append(buf, e)
+			}
+		}
+	})
+
+	c := &RCUMap[K, V]{hasher: m.hasher}
+	n := uint64(defaultRCUBuckets)
+	for uint64(len(buf)) > n*rcuMaxLoad {
+		n *= 2
+	}
+	t := newRCUTable[K, V](n)
+	for _, e := range buf {
+		t.put(e)
+	}
+	c.table.Store(t)
+	c.size.Store(int64(len(buf)))
+	return c
+}
+
+// ExactLen 冻结所有桶后统计未过期的元素个数，与同一时刻的 Snapshot().Len() 一致。
+// 与 Len 相比代价较高，Len 包含尚未清理的过期元素
+func (m *RCUMap[K, V]) ExactLen() int {
+	n := 0
+	m.exclusive(func(t *rcuTable[K, V]) {
+		now := time.Now().UnixNano()
+		for e := range t.all() {
+			if !e.expired(now) {
+				n++
+			}
+		}
+	})
+	return n
+}
+
+// exclusive 冻结当前表的所有桶，将其内容复制到一张新表后交给 fn 读取或修改，最后发布新表。
+// 冻结期间写入会等待新表发布，读方在发布前读到旧表、发布后读到新表，因此 fn 的修改对读方是原子的。
+// 新表与旧表共享未修改的桶内容，fn 需通过 put/remove 修改
+func (m *RCUMap[K, V]) exclusive(fn func(t *rcuTable[K, V])) {
+	m.resizeMu.Lock()
+	defer m.resizeMu.Unlock()
+
+	old := m.table.Load()
+	t := newRCUTable[K, V](uint64(len(old.buckets)))
+	for i, b := range m.freeze(old) {
+		if len(b.entries) > 0 {
+			t.buckets[i].Store(&rcuBucket[K, V]{entries: b.entries})
+		}
+	}
+	fn(t)
+	m.table.Store(t)
+}
+
+// all 遍历表中的所有元素（包括已过期的），只能用于尚未发布或已冻结的表
+func (t *rcuTable[K, V]) all() iter.Seq[rcuEntry[K, V]] {
+	return func(yield func(rcuEntry[K, V]) bool) {
+		for i := range t.buckets {
+			for _, e := range t.buckets[i].Load().items() {
+				if !yield(e) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// put 在尚未发布的表中写入，返回是否新增了元素。桶的内容可能与旧表共享，因此总是复制后修改
+func (t *rcuTable[K, V]) put(e rcuEntry[K, V]) bool {
+	slot := &t.buckets[e.hash&t.mask]
+	b := slot.Load()
+	if i := b.find(e.hash, e.key); i >= 0 {
+		nb := &rcuBucket[K, V]{entries: slices.Clone(b.entries)}
+		nb.entries[i] = e
+		slot.Store(nb)
+		return false
+	}
+	slot.Store(&rcuBucket[K, V]{entries: append(slices.Clip(b.items()), e)})
+	return true
+}
+
+// remove 在尚未发布的表中删除 key，返回是否删除了元素
+func (t *rcuTable[K, V]) remove(hash uint64, key K) bool {
+	slot := &t.buckets[hash&t.mask]
+	b := slot.Load()
+	i := b.find(hash, key)
+	if i < 0 {
+		return false
+	}
+	var nb *rcuBucket[K, V]
+	if len(b.entries) > 1 {
+		nb = &rcuBucket[K, V]{entries: slices.Delete(slices.Clone(b.entries), i, i+1)}
+	}
+	slot.Store(nb)
+	return true
+}
@@ -0,0 +1,336 @@
+package maps
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRCUMapGrow(t *testing.T) {
+	m := NewRCUMap[int, int]()
+	const goroutines, perG = 8, 5000
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := g * perG; i < (g+1)*perG; i++ {
+				m.Set(i, i)
+				// 扩容期间写入的值不会丢失
+				v, ok := m.Get(i)
+				assert.True(t, ok)
+				assert.Equal(t, i, v)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, goroutines*perG, m.Len())
+	assert.Greater(t, len(m.table.Load().buckets), defaultRCUBuckets)
+	for i := 0; i < goroutines*perG; i++ {
+		v, ok := m.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+	assert.Equal(t, goroutines*perG, len(m.Keys()))
+}
+
+func TestRCUMapConcurrentDelete(t *testing.T) {
+	m := NewRCUMap[int, int]()
+	for i := 0; i < 10000; i++ {
+		m.Set(i, i)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// 多个 goroutine 删除同一批 key，LoadAndDelete 只会成功一次
+			for i := 0; i < 10000; i++ {
+				if _, ok := m.LoadAndDelete(i); ok {
+					m.Set(-i-1, g)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10000, m.Len())
+	for i := 0; i < 10000; i++ {
+		_, ok := m.Get(i)
+		assert.False(t, ok)
+	}
+}
+
+func TestRCUMapClear(t *testing.T) {
+	m := NewRCUMap[string, int]()
+	for i := 0; i < 1000; i++ {
+		m.Set(strconv.Itoa(i), i)
+	}
+	m.Clear()
+	assert.Equal(t, 0, m.Len())
+	assert.Empty(t, m.Keys())
+	assert.Equal(t, defaultRCUBuckets, len(m.table.Load().buckets))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				m.Set(strconv.Itoa(g*1000+i), i)
+				if i%300 == 0 {
+					m.Clear()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	// 计数与实际内容一致
+	assert.Equal(t, len(m.Keys()), m.Len())
+}
+
+func TestRCUMapWithHasher(t *testing.T) {
+	// 所有 key 落在同一个桶
+	m := NewRCUMap[int, string](WithRCUHasher(func(int) uint64 { return 0 }))
+	for i := 0; i < 100; i++ {
+		m.Set(i, strconv.Itoa(i))
+	}
+	m.Delete(50)
+	assert.Equal(t, 99, m.Len())
+	v, ok := m.Get(99)
+	assert.True(t, ok)
+	assert.Equal(t, "99", v)
+	_, ok = m.Get(50)
+	assert.False(t, ok)
+}
+
+func TestRCUMapTTL(t *testing.T) {
+	m := NewRCUMap[string, int]()
+	m.SetWithTTL("short", 1, 20*time.Millisecond)
+	m.SetWithTTL("long", 2, time.Hour)
+	m.Set("forever", 3)
+
+	_, ttl, ok := m.GetWithTTL("long")
+	assert.True(t, ok)
+	assert.True(t, ttl > 59*time.Minute)
+	_, ttl, ok = m.GetWithTTL("forever")
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), ttl)
+
+	// Compute 保留原有的过期时间，Set 清除过期时间
+	m.Compute("long", func(old int, ok bool) (int, bool) { return old + 1, true })
+	_, ttl, _ = m.GetWithTTL("long")
+	assert.True(t, ttl > 59*time.Minute)
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = m.Get("short")
+	assert.False(t, ok)
+	assert.Equal(t, 2, m.Len())
+	assert.ElementsMatch(t, []string{"long", "forever"}, m.Keys())
+
+	// 过期的 key 对 GetOrSet 来说不存在
+	m.SetWithTTL("short", 1, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	val, loaded := m.GetOrSet("short", 10)
+	assert.False(t, loaded)
+	assert.Equal(t, 10, val)
+	_, ttl, _ = m.GetWithTTL("short")
+	assert.Equal(t, time.Duration(0), ttl)
+	assert.Equal(t, 3, m.Len())
+}
+
+func TestRCUMapJanitor(t *testing.T) {
+	m := NewRCUMap[int, int]()
+	for i := 0; i < 100; i++ {
+		m.SetWithTTL(i, i, 10*time.Millisecond)
+	}
+	m.Set(-1, -1)
+	m.StartJanitor(5 * time.Millisecond)
+	defer m.StopJanitor()
+
+	// 清理不依赖读取
+	assert.Eventually(t, func() bool { return m.Len() == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 1, m.ExactLen())
+}
+
+func TestRCUMapBatch(t *testing.T) {
+	m := NewRCUMap[string, int]()
+	m.SetMany(map[string]int{"a": 1, "b": 2, "c": 3})
+	assert.Equal(t, map[string]int{"a": 1, "c": 3}, m.GetMany([]string{"a", "c", "missing"}))
+	m.DeleteMany([]string{"a", "missing"})
+	assert.Equal(t, 2, m.Len())
+
+	items := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		items[strconv.Itoa(i)] = i
+	}
+	m.SetManyAtomic(items)
+	assert.Equal(t, 1002, m.Len())
+	assert.Equal(t, 1002, m.ExactLen())
+	assert.Greater(t, len(m.table.Load().buckets), defaultRCUBuckets)
+	v, ok := m.Get("999")
+	assert.True(t, ok)
+	assert.Equal(t, 999, v)
+
+	m.DeleteManyAtomic([]string{"1", "2", "missing"})
+	assert.Equal(t, 1000, m.Len())
+	_, ok = m.Get("1")
+	assert.False(t, ok)
+}
+
+func TestRCUMapAtomicVisibility(t *testing.T) {
+	m := NewRCUMap[int, int]()
+	keys := make([]int, 64)
+	for i := range keys {
+		keys[i] = i
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			// 读方要么看到某次批量写入的全部 key，要么一个都看不到
+			sn := m.Snapshot()
+			assert.True(t, sn.Len() == 0 || sn.Len() == len(keys), "partial batch: %d", sn.Len())
+		}
+	}()
+
+	for round := 0; round < 200; round++ {
+		items := make(map[int]int, len(keys))
+		for _, k := range keys {
+			items[k] = round
+		}
+		m.SetManyAtomic(items)
+		m.DeleteManyAtomic(keys)
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestRCUMapSnapshotClone(t *testing.T) {
+	m := NewRCUMap[string, int]()
+	m.Set("a", 1)
+	m.SetWithTTL("b", 2, time.Hour)
+	m.SetWithTTL("expired", 3, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	sn := m.Snapshot()
+	c := m.Clone()
+	m.Set("a", 10)
+	m.Delete("b")
+
+	// 之后的修改不影响 Snapshot 与 Clone
+	assert.Equal(t, 2, sn.Len())
+	v, _ := sn.Get("a")
+	assert.Equal(t, 1, v)
+	assert.Equal(t, 2, c.Len())
+	_, ttl, ok := c.GetWithTTL("b")
+	assert.True(t, ok)
+	assert.True(t, ttl > 59*time.Minute)
+
+	// 冻结后写入仍然可以继续
+	m.Set("c", 3)
+	assert.Equal(t, 2, m.ExactLen())
+}
+
+func TestRCUMapEncoding(t *testing.T) {
+	m := NewRCUMap[int, string]()
+	for i, v := range []string{"a", "b", "c", "d"} {
+		m.Set(i*5, v)
+	}
+	m.SetWithTTL(100, "expired", time.Nanosecond)
+	m.SetWithTTL(200, "ttl", time.Hour)
+	time.Sleep(time.Millisecond)
+
+	data, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"0":"a","5":"b","10":"c","15":"d","200":"ttl"}`, string(data))
+	// 可以直接解码到零值的 RCUMap 中
+	var restored RCUMap[int, string]
+	assert.NoError(t, json.Unmarshal(data, &restored))
+	assert.Equal(t, 5, restored.Len())
+
+	var buf bytes.Buffer
+	assert.NoError(t, gob.NewEncoder(&buf).Encode(m))
+	var decoded RCUMap[int, string]
+	assert.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+	assert.Equal(t, 5, decoded.Len())
+	_, ttl, ok := decoded.GetWithTTL(200)
+	assert.True(t, ok)
+	assert.True(t, ttl > 59*time.Minute)
+
+	// 与 ShardMap 的 gob 格式相同
+	bin, err := m.MarshalBinary()
+	assert.NoError(t, err)
+	sm := NewShardMap[int, string]()
+	assert.NoError(t, sm.UnmarshalBinary(bin))
+	assert.Equal(t, 5, sm.Len())
+
+	var zero RCUMap[int, string]
+	data, err = json.Marshal(&zero)
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, string(data))
+}
+
+// ---------------- 不同读写比例下的对比 ----------------
+
+// benchReadWrite 预先写入 64K 个 key，然后并发执行读写，每 100 次操作中有 readPct 次读
+func benchReadWrite(b *testing.B, readPct int, get func(string), set func(string, int)) {
+	keys := make([]string, 1<<16)
+	for i := range keys {
+		keys[i] = "k" + strconv.Itoa(i)
+		set(keys[i], i)
+	}
+
+	b.SetParallelism(8)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := keys[(i*7919)&0xFFFF]
+			if i%100 < readPct {
+				get(key)
+			} else {
+				set(key, i)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkReadWriteRatio(b *testing.B) {
+	for _, readPct := range []int{100, 99, 90, 50} {
+		b.Run("read"+strconv.Itoa(readPct)+"/RCUMap", func(b *testing.B) {
+			m := NewRCUMap[string, int]()
+			benchReadWrite(b, readPct, func(k string) { m.Get(k) }, m.Set)
+		})
+		b.Run("read"+strconv.Itoa(readPct)+"/ShardMap", func(b *testing.B) {
+			m := NewShardMap[string, int](WithShardCount(16))
+			benchReadWrite(b, readPct, func(k string) { m.Get(k) }, m.Set)
+		})
+		b.Run("read"+strconv.Itoa(readPct)+"/ConcurrentMap", func(b *testing.B) {
+			m := NewConcurrentMap[string, int]()
+			benchReadWrite(b, readPct, func(k string) { m.Get(k) }, m.Set)
+		})
+		b.Run("read"+strconv.Itoa(readPct)+"/SyncMap", func(b *testing.B) {
+			var m sync.Map
+			benchReadWrite(b, readPct, func(k string) { m.Load(k) }, func(k string, v int) { m.Store(k, v) })
+		})
+	}
+}
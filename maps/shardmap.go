@@ -1,101 +1,132 @@
 package maps
 
 import (
-	"fmt"
-	"hash/fnv"
+	"iter"
+	"math/bits"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"github.com/Ri0nGo/gokit/internal/hashing"
 )
 
 var defaultShardCnt uint64 = 16
 
 type shard[K comparable, V any] struct {
 	container map[K]V
-	mu        sync.RWMutex
+	// expires 记录设置了 TTL 的 key 的过期时间（UnixNano），没有 TTL 的 key 不会出现在这里
+	expires map[K]int64
+	// policy 为 nil 表示不限制容量
+	policy   EvictPolicy[K]
+	capacity int
+	// migrated 为 true 表示该分片已在 Resize 中迁移到新的分片表，拿到锁后需要重新定位分片
+	migrated bool
+	// version 每次写入或删除时加一，用于 Txn 的冲突检测，需持有写锁修改
+	version uint64
+	mu      sync.RWMutex
+	stats   shardCounters
+}
+
+// shardTable 分片表，Resize 时整体替换
+type shardTable[K comparable, V any] struct {
+	shards []*shard[K, V]
+	mask   uint64
 }
 
 type ShardMap[K comparable, V any] struct {
-	shards    []*shard[K, V]
-	hasher    func(K) uint64
-	count     uint64
-	shardMask uint64
-	total     atomic.Uint64
+	table   atomic.Pointer[shardTable[K, V]]
+	hasher  func(K) uint64
+	total   atomic.Uint64
+	onEvict func(K, V)
+
+	// statsEnabled 为 true 时统计命中率和锁等待时间，retired 累计 Resize 前旧分片的统计
+	statsEnabled bool
+	retired      shardCounters
+
+	// 以下字段用于 Resize 时重建分片
+	newPolicy func() EvictPolicy[K]
+	capacity  uint64
+	maxLoad   int
+	resizeMu  sync.Mutex
+	growing   atomic.Bool
+
+	janitorMu sync.Mutex
+	janitor   *janitor
+
+	// wal 不为 nil 时表示通过 Open 打开的持久化 ShardMap
+	wal *wal[K, V]
+
+	watchers watchHub[K, V]
 }
 
-// NewShardMap 创建分片数为 >= shardCnt 的最小 2^n（若传入 <=0，则使用默认值）
-func NewShardMap[K comparable, V any](shardCnt uint64) *ShardMap[K, V] {
-	if shardCnt <= 0 {
-		shardCnt = defaultShardCnt
-	}
-	// 向上取最接近的 2^n
-	count := roundUpToPower2(shardCnt)
+// NewShardMap 创建 ShardMap，默认 16 个分片，可通过 WithShardCount 等 Option 调整
+func NewShardMap[K comparable, V any](opts ...Option) *ShardMap[K, V] {
+	return newShardMap[K, V](parseOptions(opts))
+}
 
+func newShardMap[K comparable, V any](o *options) *ShardMap[K, V] {
 	sm := &ShardMap[K, V]{
-		shards:    make([]*shard[K, V], count),
-		hasher:    defaultHasher[K],
-		count:     count,
-		shardMask: count - 1,
-	}
-	// 初始化分段后的map
-	for i := 0; i < int(count); i++ {
-		sm.shards[i] = &shard[K, V]{
-			container: make(map[K]V),
-		}
+		hasher:    hasherFunc[K](o),
+		onEvict:   onEvictFunc[K, V](o),
+		newPolicy: newPolicyFunc[K](o),
+		capacity:  o.capacity,
+		maxLoad:   int(o.maxLoad),
+
+		statsEnabled: o.stats,
 	}
+	// 向上取最接近的 2^n
+	count := sm.limitShardCount(roundUpToPower2(o.shardCnt))
+	sm.table.Store(sm.newTable(count, int(o.initCapacity/count)))
 	return sm
 }
 
-// Set 设置 key, value
+// Set 设置 key, value，若 key 之前设置过 TTL，则会清除其过期时间
 func (s *ShardMap[K, V]) Set(key K, val V) {
-	shardMap := s.getShard(key)
-	shardMap.mu.Lock()
-
-	if _, existed := shardMap.container[key]; !existed {
-		s.total.Add(1)
-	}
-	shardMap.container[key] = val
+	shardMap := s.lockShard(key)
+	evicted, ok := s.setLocked(shardMap, key, val, 0)
 	shardMap.mu.Unlock()
+	s.notifyEvict(evicted, ok)
 }
 
-// Get 获取值
+// Get 获取值，已过期的 key 会被顺带删除
 func (s *ShardMap[K, V]) Get(key K) (V, bool) {
-	shardMap := s.getShard(key)
-	shardMap.mu.RLock()
+	shardMap := s.rlockShard(key)
 	val, ok := shardMap.container[key]
-	shardMap.mu.RUnlock()
+	expired := ok && shardMap.expired(key, time.Now().UnixNano())
+	s.recordLookup(shardMap, ok && !expired)
+	if expired {
+		shardMap.unlockRead()
+		s.expireKey(key)
+		var zero V
+		return zero, false
+	}
+	if ok {
+		shardMap.touchLocked(key)
+	}
+	shardMap.unlockRead()
 	return val, ok
 }
 
 // Delete 删除 key
 func (s *ShardMap[K, V]) Delete(key K) {
-	shardMap := s.getShard(key)
-	shardMap.mu.Lock()
+	shardMap := s.lockShard(key)
 	if _, existed := shardMap.container[key]; existed {
-		s.total.Add(^uint64(0)) // -1 using atomic.Uint64 (Add with wrap); or use Add(^uint64(0)) equals -1
-		// Alternatively: s.total.Add(^uint64(0)) is a trick; clearer:
-		// s.total.Add(^uint64(0)) // subtract 1
-		// But for readability, use s.total.Add(^uint64(0))
+		s.removeLocked(shardMap, key)
 	}
-	delete(shardMap.container, key)
 	shardMap.mu.Unlock()
 }
 
 // Len 返回近似总元素个数（极快，几乎无锁）
-func (s *ShardMap[K, V]) Len() uint64 {
-	return s.total.Load()
+func (s *ShardMap[K, V]) Len() int {
+	return int(s.total.Load())
 }
 
 // Keys 返回所有 key（会分配内存，但比全局锁快很多）
 func (s *ShardMap[K, V]) Keys() []K {
 	// 预分配近似容量
 	keys := make([]K, 0, s.Len())
-
-	for _, sm := range s.shards {
-		sm.mu.RLock()
-		for k := range sm.container {
-			keys = append(keys, k)
-		}
-		sm.mu.RUnlock()
+	for k := range s.All() {
+		keys = append(keys, k)
 	}
 	return keys
 }
@@ -103,91 +134,318 @@ func (s *ShardMap[K, V]) Keys() []K {
 // Values 返回所有 value
 func (s *ShardMap[K, V]) Values() []V {
 	values := make([]V, 0, s.Len())
-	for _, sm := range s.shards {
-		sm.mu.RLock()
-		for _, v := range sm.container {
-			values = append(values, v)
-		}
-		sm.mu.RUnlock()
+	for _, v := range s.All() {
+		values = append(values, v)
 	}
 	return values
 }
 
-// Range 遍历所有键值, f返回true则停止。通过 snapshot（复制）实现，
+// Range 遍历所有键值, f返回true则停止。逐个分片复制后再回调，
 // 回调中可以安全调用 Set/Delete（但不保证写入能被当前 Range 看到）
 func (s *ShardMap[K, V]) Range(f func(key K, value V) (stop bool)) {
-	type pair struct {
-		k K
-		v V
+	for k, v := range s.All() {
+		if f(k, v) {
+			return
+		}
 	}
-	items := make([]pair, 0, s.Len())
+}
 
-	for _, sh := range s.shards {
-		sh.mu.RLock()
-		for k, v := range sh.container {
-			items = append(items, pair{k, v})
+// All 返回遍历所有键值的迭代器。同一时刻只复制一个分片的数据，不会一次性复制整个 map，
+// 循环体中可以安全调用 Set/Delete（但不保证写入能被当前遍历看到）。
+// 遍历过程中发生 Resize 时，会切换到新的分片表继续遍历，不会重复返回已遍历过的 key。
+// 各分片在不同时刻被复制，结果不保证跨分片一致，需要一致的视图时使用 Snapshot
+func (s *ShardMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for e := range s.entries() {
+			if !yield(e.key, e.val) {
+				return
+			}
 		}
-		sh.mu.RUnlock()
 	}
+}
 
-	for _, it := range items {
-		if f(it.k, it.v) {
-			return
+// KeysSeq 返回遍历所有 key 的迭代器，见 All
+func (s *ShardMap[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range s.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesSeq 返回遍历所有 value 的迭代器，见 All
+func (s *ShardMap[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range s.All() {
+			if !yield(v) {
+				return
+			}
 		}
 	}
 }
 
 // ---------------- 辅助函数 ---------------- //
 
-// 获取 key 对应的 shard
-func (s *ShardMap[K, V]) getShard(key K) *shard[K, V] {
-	idx := s.hasher(key) & s.shardMask
-	return s.shards[idx]
+// entry 一个键值对，expireAt 为 0 表示永不过期
+type entry[K comparable, V any] struct {
+	key      K
+	val      V
+	expireAt int64
 }
 
-// defaultHasher 指向 fastHash，便于以后替换
-func defaultHasher[K comparable](k K) uint64 {
-	return fastHash(k)
-}
-
-// fastHash 对常见类型做特化，fallback 到 fmt+fnv
-func fastHash[K comparable](k K) uint64 {
-	switch x := any(k).(type) {
-	case string:
-		// FNV-1a inline (no allocation)
-		var h uint64 = 1469598103934665603
-		for i := 0; i < len(x); i++ {
-			h ^= uint64(x[i])
-			h *= 1099511628211
+// entries 逐个分片遍历未过期的元素，供 All 与序列化使用
+func (s *ShardMap[K, V]) entries() iter.Seq[entry[K, V]] {
+	return func(yield func(entry[K, V]) bool) {
+		var (
+			buf []entry[K, V]
+			// visited 发生 Resize 后用于判断 key 是否已经遍历过
+			visited func(h uint64) bool
+		)
+		t := s.table.Load()
+		if t == nil {
+			// 零值的 ShardMap 在第一次解码前没有分片表
+			return
+		}
+		for i := 0; i < len(t.shards); i++ {
+			var migrated bool
+			buf, migrated = t.shards[i].appendEntries(buf[:0], time.Now().UnixNano())
+			if migrated {
+				// 旧表中下标小于 i 的分片已经遍历过，从新表的第一个分片重新开始
+				visited = visitedBefore(visited, t.mask, i)
+				t = s.table.Load()
+				i = -1
+				continue
+			}
+			for _, e := range buf {
+				if visited != nil && visited(s.hasher(e.key)) {
+					continue
+				}
+				if !yield(e) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// setLocked 写入 key，expireAt 为 0 表示永不过期，调用方需持有 sh 的写锁。
+// 分片已满时会先淘汰一个元素，并将其返回，调用方应在释放锁后调用 notifyEvict
+func (s *ShardMap[K, V]) setLocked(sh *shard[K, V], key K, val V, expireAt int64) (evicted entry[K, V], ok bool) {
+	old, existed := sh.container[key]
+	if s.watchers.active() {
+		hasOld := existed && !sh.expired(key, time.Now().UnixNano())
+		defer s.watchers.emit(Event[K, V]{Type: EventSet, Key: key, Old: old, HasOld: hasOld, New: val})
+	}
+	sh.version++
+	if existed {
+		if sh.policy != nil {
+			sh.policy.Access(key)
 		}
-		return h
-	case int:
-		u := uint64(x)
-		u = (u ^ (u >> 30)) * 0xbf58476d1ce4e5b9
-		u = (u ^ (u >> 27)) * 0x94d049bb133111eb
-		return u ^ (u >> 31)
-	case uint:
-		u := uint64(x)
-		u = (u ^ (u >> 30)) * 0xbf58476d1ce4e5b9
-		u = (u ^ (u >> 27)) * 0x94d049bb133111eb
-		return u ^ (u >> 31)
-	case int64:
-		u := uint64(x)
-		u = (u ^ (u >> 30)) * 0xbf58476d1ce4e5b9
-		u = (u ^ (u >> 27)) * 0x94d049bb133111eb
-		return u ^ (u >> 31)
-	case uint64:
-		u := uint64(x)
-		u = (u ^ (u >> 30)) * 0xbf58476d1ce4e5b9
-		u = (u ^ (u >> 27)) * 0x94d049bb133111eb
-		return u ^ (u >> 31)
-	// 根据需要可以继续添加 int32/uint32/[]byte 等特化
-	default:
-		s := fmt.Sprintf("%v", k)
-		h := fnv.New64a()
-		_, _ = h.Write([]byte(s))
-		return h.Sum64()
+	} else {
+		evicted, ok = s.addLocked(sh, key)
+		s.total.Add(1)
+	}
+	sh.container[key] = val
+	if expireAt > 0 {
+		sh.expires[key] = expireAt
+	} else {
+		delete(sh.expires, key)
+	}
+	if s.wal != nil {
+		s.wal.append(walRecord[K, V]{Op: walSet, Key: key, Val: val, ExpireAt: expireAt})
+	}
+	return evicted, ok
+}
+
+// removeLocked 删除一个已存在的 key 并维护计数，调用方需持有 sh 的写锁
+func (s *ShardMap[K, V]) removeLocked(sh *shard[K, V], key K) {
+	s.deleteLocked(sh, key, EventDelete)
+}
+
+// expireLocked 删除一个已过期的 key，与 removeLocked 的区别仅在于通知的事件类型
+func (s *ShardMap[K, V]) expireLocked(sh *shard[K, V], key K) {
+	s.deleteLocked(sh, key, EventExpire)
+}
+
+func (s *ShardMap[K, V]) deleteLocked(sh *shard[K, V], key K, typ EventType) {
+	if s.watchers.active() {
+		s.watchers.emit(Event[K, V]{Type: typ, Key: key, Old: sh.container[key], HasOld: true})
+	}
+	sh.version++
+	delete(sh.container, key)
+	delete(sh.expires, key)
+	if sh.policy != nil {
+		sh.policy.Remove(key)
+	}
+	s.total.Add(^uint64(0)) // 相当于 -1
+	if s.wal != nil {
+		s.wal.append(walRecord[K, V]{Op: walDelete, Key: key})
+	}
+}
+
+// addLocked 将 key 放入分片，不维护计数，供 setLocked 与 Resize 迁移使用，调用方需持有 sh 的写锁
+func (s *ShardMap[K, V]) addLocked(sh *shard[K, V], key K) (evicted entry[K, V], ok bool) {
+	if sh.policy != nil {
+		evicted, ok = s.evictLocked(sh)
+		sh.policy.Add(key)
+	}
+	if s.maxLoad > 0 && len(sh.container) >= s.maxLoad {
+		s.maybeGrow()
+	}
+	return evicted, ok
+}
+
+// evictLocked 分片已满时按淘汰策略删除一个元素，调用方需持有 sh 的写锁
+func (s *ShardMap[K, V]) evictLocked(sh *shard[K, V]) (entry[K, V], bool) {
+	if len(sh.container) < sh.capacity {
+		return entry[K, V]{}, false
+	}
+	key, ok := sh.policy.Victim()
+	if !ok {
+		return entry[K, V]{}, false
+	}
+	val := sh.container[key]
+	s.removeLocked(sh, key)
+	return entry[K, V]{key: key, val: val}, true
+}
+
+// appendEntries 将分片中未过期的键值对追加到 buf 中，分片已被迁移时返回 migrated 为 true
+func (sh *shard[K, V]) appendEntries(buf []entry[K, V], now int64) (_ []entry[K, V], migrated bool) {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	if sh.migrated {
+		return buf, true
+	}
+	for k, v := range sh.container {
+		if !sh.expired(k, now) {
+			buf = append(buf, entry[K, V]{key: k, val: v, expireAt: sh.expires[k]})
+		}
+	}
+	return buf, false
+}
+
+// visitedBefore 在 prev 的基础上，将 mask 对应的分片表中下标小于 done 的分片标记为已遍历
+func visitedBefore(prev func(h uint64) bool, mask uint64, done int) func(h uint64) bool {
+	return func(h uint64) bool {
+		return int(h&mask) < done || (prev != nil && prev(h))
+	}
+}
+
+// notifyEvict 在锁外执行淘汰回调
+func (s *ShardMap[K, V]) notifyEvict(e entry[K, V], ok bool) {
+	if ok && s.onEvict != nil {
+		s.onEvict(e.key, e.val)
+	}
+}
+
+// lockRead 读操作加锁。启用淘汰策略时读操作也要更新策略状态，因此使用写锁
+func (sh *shard[K, V]) lockRead() {
+	if sh.policy != nil {
+		sh.mu.Lock()
+	} else {
+		sh.mu.RLock()
+	}
+}
+
+func (sh *shard[K, V]) unlockRead() {
+	if sh.policy != nil {
+		sh.mu.Unlock()
+	} else {
+		sh.mu.RUnlock()
+	}
+}
+
+// touchLocked 记录一次访问，调用方需通过 lockRead 或写锁持有 sh
+func (sh *shard[K, V]) touchLocked(key K) {
+	if sh.policy != nil {
+		sh.policy.Access(key)
+	}
+}
+
+// newTable 按当前配置创建 count 个分片
+func (s *ShardMap[K, V]) newTable(count uint64, initCap int) *shardTable[K, V] {
+	t := &shardTable[K, V]{
+		shards: make([]*shard[K, V], count),
+		mask:   count - 1,
+	}
+	// 容量按分片拆分，前 capacity%count 个分片各多分一个，总和恰好等于 capacity
+	shardCap, extra := s.capacity/count, s.capacity%count
+	// 初始化分段后的map
+	for i := range t.shards {
+		t.shards[i] = &shard[K, V]{
+			container: make(map[K]V, initCap),
+			expires:   make(map[K]int64),
+		}
+		if s.newPolicy != nil {
+			t.shards[i].policy = s.newPolicy()
+			t.shards[i].capacity = int(shardCap)
+			if uint64(i) < extra {
+				t.shards[i].capacity++
+			}
+		}
+	}
+	return t
+}
+
+// lockShard 获取 key 对应的 shard 并加写锁；分片已被迁移时重新定位
+func (s *ShardMap[K, V]) lockShard(key K) *shard[K, V] {
+	h := s.hasher(key)
+	for {
+		t := s.table.Load()
+		sh := t.shards[h&t.mask]
+		s.lockTimed(sh)
+		if !sh.migrated {
+			return sh
+		}
+		sh.mu.Unlock()
+	}
+}
+
+// lockTimed 对 sh 加写锁，开启统计时记录等待时间
+func (s *ShardMap[K, V]) lockTimed(sh *shard[K, V]) {
+	if s.statsEnabled {
+		start := time.Now()
+		sh.mu.Lock()
+		sh.stats.recordWait(start)
+	} else {
+		sh.mu.Lock()
+	}
+}
+
+// rlockShard 获取 key 对应的 shard 并通过 lockRead 加锁，需使用 unlockRead 解锁
+func (s *ShardMap[K, V]) rlockShard(key K) *shard[K, V] {
+	h := s.hasher(key)
+	for {
+		t := s.table.Load()
+		sh := t.shards[h&t.mask]
+		if s.statsEnabled {
+			start := time.Now()
+			sh.lockRead()
+			sh.stats.recordWait(start)
+		} else {
+			sh.lockRead()
+		}
+		if !sh.migrated {
+			return sh
+		}
+		sh.unlockRead()
+	}
+}
+
+// defaultHasher 使用 hashing.Hash，所有类型都带进程内随机种子，外部无法构造大量冲突的 key
+func defaultHasher[K comparable](k K) uint64 {
+	return hashing.Hash(k)
+}
+
+// limitShardCount 设置了容量时，分片数不超过容量（向下取 2^n），保证每个分片至少能存放一个元素
+func (s *ShardMap[K, V]) limitShardCount(count uint64) uint64 {
+	if s.capacity > 0 && count > s.capacity {
+		return 1 << (bits.Len64(s.capacity) - 1)
 	}
+	return count
 }
 
 // roundUpToPower2 向上取最近的 2^n（传入非 0）
@@ -0,0 +1,200 @@
+package maps
+
+import (
+	"slices"
+	"time"
+)
+
+// SetMany 批量写入，按分片分组后每个分片只加一次锁。
+// 不同分片之间不保证原子性，读方可能看到部分写入；需要原子性时使用 SetManyAtomic
+func (s *ShardMap[K, V]) SetMany(items map[K]V) {
+	t := s.table.Load()
+	groups := make([][]entry[K, V], len(t.shards))
+	perShard := len(items)/len(t.shards) + 1
+	for k, v := range items {
+		i := s.hasher(k) & t.mask
+		if groups[i] == nil {
+			groups[i] = make([]entry[K, V], 0, perShard)
+		}
+		groups[i] = append(groups[i], entry[K, V]{key: k, val: v})
+	}
+
+	var evicted []entry[K, V]
+	for i, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		sh := t.shards[i]
+		s.lockTimed(sh)
+		if sh.migrated {
+			// 分组后分片被 Resize 迁移，逐个写入
+			sh.mu.Unlock()
+			for _, e := range group {
+				s.Set(e.key, e.val)
+			}
+			continue
+		}
+		for _, e := range group {
+			if ev, ok := s.setLocked(sh, e.key, e.val, 0); ok {
+				evicted = append(evicted, ev)
+			}
+		}
+		sh.mu.Unlock()
+	}
+	for _, e := range evicted {
+		s.notifyEvict(e, true)
+	}
+}
+
+// GetMany 批量读取，返回存在且未过期的键值对
+func (s *ShardMap[K, V]) GetMany(keys []K) map[K]V {
+	res := make(map[K]V, len(keys))
+	var expired []K
+	now := time.Now().UnixNano()
+	t, groups := s.groupByShard(keys)
+	for i, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		sh := t.shards[i]
+		sh.lockRead()
+		if sh.migrated {
+			sh.unlockRead()
+			for _, k := range group {
+				if v, ok := s.Get(k); ok {
+					res[k] = v
+				}
+			}
+			continue
+		}
+		for _, k := range group {
+			v, ok := sh.container[k]
+			if ok && sh.expired(k, now) {
+				expired = append(expired, k)
+				ok = false
+			}
+			s.recordLookup(sh, ok)
+			if ok {
+				sh.touchLocked(k)
+				res[k] = v
+			}
+		}
+		sh.unlockRead()
+	}
+	for _, k := range expired {
+		s.expireKey(k)
+	}
+	return res
+}
+
+// DeleteMany 批量删除，按分片分组后每个分片只加一次锁
+func (s *ShardMap[K, V]) DeleteMany(keys []K) {
+	t, groups := s.groupByShard(keys)
+	for i, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		sh := t.shards[i]
+		s.lockTimed(sh)
+		if sh.migrated {
+			sh.mu.Unlock()
+			for _, k := range group {
+				s.Delete(k)
+			}
+			continue
+		}
+		for _, k := range group {
+			if _, ok := sh.container[k]; ok {
+				s.removeLocked(sh, k)
+			}
+		}
+		sh.mu.Unlock()
+	}
+}
+
+// SetManyAtomic 原子地批量写入：按分片下标从小到大锁住所有涉及的分片后再写入，
+// 读方要么看不到任何写入，要么看到全部写入。开启容量限制时，写入的 key 之间也可能相互淘汰
+func (s *ShardMap[K, V]) SetManyAtomic(items map[K]V) {
+	keys := make([]K, 0, len(items))
+	for k := range items {
+		keys = append(keys, k)
+	}
+	var evicted []entry[K, V]
+	s.withShardsLocked(keys, func(shardOf func(K) *shard[K, V]) {
+		for _, k := range keys {
+			if e, ok := s.setLocked(shardOf(k), k, items[k], 0); ok {
+				evicted = append(evicted, e)
+			}
+		}
+	})
+	for _, e := range evicted {
+		s.notifyEvict(e, true)
+	}
+}
+
+// DeleteManyAtomic 原子地批量删除，加锁方式同 SetManyAtomic
+func (s *ShardMap[K, V]) DeleteManyAtomic(keys []K) {
+	s.withShardsLocked(keys, func(shardOf func(K) *shard[K, V]) {
+		for _, k := range keys {
+			sh := shardOf(k)
+			if _, ok := sh.container[k]; ok {
+				s.removeLocked(sh, k)
+			}
+		}
+	})
+}
+
+// groupByShard 按当前分片表将 keys 分组，返回的 groups 下标与分片下标一一对应
+func (s *ShardMap[K, V]) groupByShard(keys []K) (*shardTable[K, V], [][]K) {
+	t := s.table.Load()
+	groups := make([][]K, len(t.shards))
+	for _, k := range keys {
+		i := s.hasher(k) & t.mask
+		groups[i] = append(groups[i], k)
+	}
+	return t, groups
+}
+
+// withShardsLocked 按分片下标从小到大锁住 keys 涉及的所有分片后调用 fn，
+// 与 Resize 的加锁顺序一致，因此不会死锁。fn 中通过 shardOf 获取 key 所在的分片
+func (s *ShardMap[K, V]) withShardsLocked(keys []K, fn func(shardOf func(K) *shard[K, V])) {
+	t, shards := s.lockKeys(keys)
+	defer unlockAll(shards)
+
+	fn(func(k K) *shard[K, V] {
+		return t.shards[s.hasher(k)&t.mask]
+	})
+}
+
+// lockKeys 按分片下标从小到大锁住 keys 涉及的分片，返回加锁时的分片表和已加锁的分片。
+// 遇到已被迁移的分片时全部解锁并按新的分片表重试
+func (s *ShardMap[K, V]) lockKeys(keys []K) (*shardTable[K, V], []*shard[K, V]) {
+retry:
+	for {
+		t := s.table.Load()
+		idx := make([]uint64, 0, len(keys))
+		for _, k := range keys {
+			idx = append(idx, s.hasher(k)&t.mask)
+		}
+		slices.Sort(idx)
+		idx = slices.Compact(idx)
+
+		shards := make([]*shard[K, V], 0, len(idx))
+		for _, i := range idx {
+			sh := t.shards[i]
+			s.lockTimed(sh)
+			shards = append(shards, sh)
+			if sh.migrated {
+				unlockAll(shards)
+				continue retry
+			}
+		}
+		return t, shards
+	}
+}
+
+func unlockAll[K comparable, V any](shards []*shard[K, V]) {
+	for _, sh := range shards {
+		sh.mu.Unlock()
+	}
+}
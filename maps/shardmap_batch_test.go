@@ -0,0 +1,141 @@
+package maps
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardMapBatch(t *testing.T) {
+	testCase := []struct {
+		name       string
+		setMany    func(m *ShardMap[int, int], items map[int]int)
+		deleteMany func(m *ShardMap[int, int], keys []int)
+	}{
+		{
+			name:       "grouped",
+			setMany:    (*ShardMap[int, int]).SetMany,
+			deleteMany: (*ShardMap[int, int]).DeleteMany,
+		},
+		{
+			name:       "atomic",
+			setMany:    (*ShardMap[int, int]).SetManyAtomic,
+			deleteMany: (*ShardMap[int, int]).DeleteManyAtomic,
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewShardMap[int, int](WithShardCount(8))
+			items := make(map[int]int)
+			for i := 0; i < 1000; i++ {
+				items[i] = i * 2
+			}
+			tc.setMany(m, items)
+			assert.Equal(t, 1000, m.Len())
+
+			got := m.GetMany([]int{1, 2, 3, 5000})
+			assert.Equal(t, map[int]int{1: 2, 2: 4, 3: 6}, got)
+
+			var keys []int
+			for i := 0; i < 1000; i += 2 {
+				keys = append(keys, i)
+			}
+			tc.deleteMany(m, append(keys, 5000))
+			assert.Equal(t, 500, m.Len())
+			_, ok := m.Get(0)
+			assert.False(t, ok)
+			val, ok := m.Get(1)
+			assert.True(t, ok)
+			assert.Equal(t, 2, val)
+		})
+	}
+}
+
+func TestShardMapGetManyExpired(t *testing.T) {
+	m := NewShardMap[string, int](WithShardCount(4))
+	m.SetWithTTL("a", 1, 10*time.Millisecond)
+	m.Set("b", 2)
+	time.Sleep(30 * time.Millisecond)
+
+	assert.Equal(t, map[string]int{"b": 2}, m.GetMany([]string{"a", "b"}))
+	assert.Equal(t, 1, m.Len())
+}
+
+func TestShardMapSetManyAtomicVisibility(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(16))
+	keys := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for round := 1; round <= 500; round++ {
+			items := make(map[int]int, len(keys))
+			for _, k := range keys {
+				items[k] = round
+			}
+			m.SetManyAtomic(items)
+		}
+	}()
+
+	// 持有所有相关分片的锁读取，不应看到只写了一部分的批次
+	for i := 0; i < 500; i++ {
+		var vals []int
+		m.withShardsLocked(keys, func(shardOf func(int) *shard[int, int]) {
+			for _, k := range keys {
+				vals = append(vals, shardOf(k).container[k])
+			}
+		})
+		for _, v := range vals {
+			assert.Equal(t, vals[0], v)
+		}
+	}
+	wg.Wait()
+}
+
+func TestShardMapBatchDuringResize(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(2))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, count := range []uint64{8, 64, 4, 32} {
+			m.Resize(count)
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		items := map[int]int{i * 3: i, i*3 + 1: i, i*3 + 2: i}
+		if i%2 == 0 {
+			m.SetMany(items)
+		} else {
+			m.SetManyAtomic(items)
+		}
+	}
+	<-done
+	assert.Equal(t, 300, m.Len())
+	assert.Equal(t, 300, len(m.GetMany(m.Keys())))
+}
+
+func BenchmarkShardMapSetMany(b *testing.B) {
+	items := make(map[int]int, 10000)
+	for i := 0; i < 10000; i++ {
+		items[i] = i
+	}
+	b.Run("Set", func(b *testing.B) {
+		m := NewShardMap[int, int](WithShardCount(16))
+		for b.Loop() {
+			for k, v := range items {
+				m.Set(k, v)
+			}
+		}
+	})
+	b.Run("SetMany", func(b *testing.B) {
+		m := NewShardMap[int, int](WithShardCount(16))
+		for b.Loop() {
+			m.SetMany(items)
+		}
+	})
+}
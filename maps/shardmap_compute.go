@@ -0,0 +1,108 @@
+package maps
+
+import "time"
+
+// GetOrSet key 存在时返回已有的值，loaded 为 true；否则写入 val 并返回 val，loaded 为 false
+func (s *ShardMap[K, V]) GetOrSet(key K, val V) (actual V, loaded bool) {
+	shardMap := s.lockShard(key)
+	old, ok := s.lookupLocked(shardMap, key)
+	s.recordLookup(shardMap, ok)
+	if ok {
+		shardMap.touchLocked(key)
+		shardMap.mu.Unlock()
+		return old, true
+	}
+	evicted, ok := s.setLocked(shardMap, key, val, 0)
+	shardMap.mu.Unlock()
+	s.notifyEvict(evicted, ok)
+	return val, false
+}
+
+// LoadAndDelete 删除 key 并返回删除前的值
+func (s *ShardMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	shardMap := s.lockShard(key)
+	defer shardMap.mu.Unlock()
+
+	val, ok := s.lookupLocked(shardMap, key)
+	if ok {
+		s.removeLocked(shardMap, key)
+	}
+	return val, ok
+}
+
+// CompareAndSwapFunc 当前值与 old 按 eq 比较相等时替换为 new，保留 key 原有的过期时间。
+// eq 在分片锁内执行
+func (s *ShardMap[K, V]) CompareAndSwapFunc(key K, old, new V, eq func(a, b V) bool) (swapped bool) {
+	shardMap := s.lockShard(key)
+	defer shardMap.mu.Unlock()
+
+	cur, ok := s.lookupLocked(shardMap, key)
+	if !ok || !eq(cur, old) {
+		return false
+	}
+	// key 已存在，覆盖写不会触发淘汰
+	s.setLocked(shardMap, key, new, shardMap.expires[key])
+	return true
+}
+
+// CompareAndDeleteFunc 当前值与 old 按 eq 比较相等时删除 key，eq 在分片锁内执行
+func (s *ShardMap[K, V]) CompareAndDeleteFunc(key K, old V, eq func(a, b V) bool) (deleted bool) {
+	shardMap := s.lockShard(key)
+	defer shardMap.mu.Unlock()
+
+	cur, ok := s.lookupLocked(shardMap, key)
+	if !ok || !eq(cur, old) {
+		return false
+	}
+	s.removeLocked(shardMap, key)
+	return true
+}
+
+// Compute 在分片锁内根据旧值计算新值：fn 返回 keep 为 false 时删除 key，否则写入 newVal。
+// 已存在的 key 保留原有的过期时间。返回计算后的值以及 key 是否存在。
+// fn 在锁内执行，不能再调用当前 ShardMap 的方法
+func (s *ShardMap[K, V]) Compute(key K, fn func(old V, ok bool) (newVal V, keep bool)) (V, bool) {
+	shardMap := s.lockShard(key)
+
+	old, ok := s.lookupLocked(shardMap, key)
+	newVal, keep := fn(old, ok)
+	if !keep {
+		if ok {
+			s.removeLocked(shardMap, key)
+		}
+		shardMap.mu.Unlock()
+		var zero V
+		return zero, false
+	}
+	evicted, evict := s.setLocked(shardMap, key, newVal, shardMap.expires[key])
+	shardMap.mu.Unlock()
+	s.notifyEvict(evicted, evict)
+	return newVal, true
+}
+
+// Upsert 插入或更新：fn 根据 key 是否存在、旧值和传入的 val 计算最终写入的值并返回。
+// fn 在锁内执行，不能再调用当前 ShardMap 的方法
+func (s *ShardMap[K, V]) Upsert(key K, val V, fn func(exist bool, old V, new V) V) V {
+	shardMap := s.lockShard(key)
+
+	old, ok := s.lookupLocked(shardMap, key)
+	res := fn(ok, old, val)
+	evicted, evict := s.setLocked(shardMap, key, res, shardMap.expires[key])
+	shardMap.mu.Unlock()
+	s.notifyEvict(evicted, evict)
+	return res
+}
+
+// lookupLocked 读取未过期的值，已过期的 key 会被直接删除，调用方需持有 sh 的写锁
+func (s *ShardMap[K, V]) lookupLocked(sh *shard[K, V], key K) (V, bool) {
+	val, ok := sh.container[key]
+	if !ok {
+		return val, false
+	}
+	if sh.expired(key, time.Now().UnixNano()) {
+		s.expireLocked(sh, key)
+		var zero V
+		return zero, false
+	}
+	return val, true
+}
@@ -0,0 +1,124 @@
+package maps
+
+import "time"
+
+// maxAutoShardCnt 自动扩容时分片数的上限
+const maxAutoShardCnt = 1 << 16
+
+// ShardCount 返回当前的分片数
+func (s *ShardMap[K, V]) ShardCount() int {
+	return len(s.table.Load().shards)
+}
+
+// Resize 将分片数调整为 >= newCount 的最小 2^n（传入 0 时不做任何调整），设置了容量时分片数不超过容量。
+// 迁移期间会锁住所有旧分片（stop-the-world），被阻塞的读写操作会在迁移完成后自动转到新分片上执行，
+// 不会丢失并发写入。启用淘汰策略时，各分片的淘汰顺序会按迁移顺序重建，新分片容量不足时会触发淘汰。
+// 不能在 Compute 等持有分片锁的回调中调用
+func (s *ShardMap[K, V]) Resize(newCount uint64) {
+	if newCount == 0 {
+		return
+	}
+	s.resizeMu.Lock()
+	defer s.resizeMu.Unlock()
+
+	s.resizeLocked(roundUpToPower2(newCount))
+}
+
+// resizeLocked 执行迁移，调用方需持有 resizeMu
+func (s *ShardMap[K, V]) resizeLocked(count uint64) {
+	count = s.limitShardCount(count)
+	old := s.table.Load()
+	if uint64(len(old.shards)) == count {
+		return
+	}
+	for _, sh := range old.shards {
+		sh.mu.Lock()
+	}
+
+	nt := s.newTable(count, s.Len()/int(count))
+	var evicted []entry[K, V]
+	now := time.Now().UnixNano()
+	for _, sh := range old.shards {
+		for k, v := range sh.container {
+			// 顺带清理已过期的 key，与惰性删除和后台清理一样发送 EventExpire 并写入 WAL
+			if sh.expired(k, now) {
+				s.expireLocked(sh, k)
+				continue
+			}
+			// 新分片表尚未发布，无需加锁
+			dst := nt.shards[s.hasher(k)&nt.mask]
+			if e, ok := s.addLocked(dst, k); ok {
+				evicted = append(evicted, e)
+			}
+			dst.container[k] = v
+			if expireAt, ok := sh.expires[k]; ok {
+				dst.expires[k] = expireAt
+			}
+		}
+		sh.migrated = true
+		s.retired.add(&sh.stats)
+	}
+	s.table.Store(nt)
+
+	for _, sh := range old.shards {
+		sh.mu.Unlock()
+	}
+	s.restartJanitor()
+	for _, e := range evicted {
+		s.notifyEvict(e, true)
+	}
+}
+
+// maybeGrow 某个分片超过 maxLoad 时在后台扩容，同一时刻只会有一个扩容任务。
+// 扩容任务会持续到所有分片都不再超载为止
+func (s *ShardMap[K, V]) maybeGrow() {
+	if !s.growing.CompareAndSwap(false, true) {
+		return
+	}
+	from := s.ShardCount()
+	go func() {
+		for {
+			s.grow(from)
+			s.growing.Store(false)
+			// 扩容期间写入的数据可能再次超载，而当时的扩容请求因 growing 为 true 被忽略了
+			if !s.overloaded() || !s.growing.CompareAndSwap(false, true) {
+				return
+			}
+			from = s.ShardCount()
+		}
+	}()
+}
+
+// grow 将分片数至少翻倍，并保证平均负载不超过 maxLoad 的一半
+func (s *ShardMap[K, V]) grow(from int) {
+	s.resizeMu.Lock()
+	defer s.resizeMu.Unlock()
+
+	// 期间已被手动 Resize 过，则放弃本次扩容
+	cur := s.ShardCount()
+	if cur != from || uint64(cur) >= s.limitShardCount(maxAutoShardCnt) {
+		return
+	}
+	target := uint64(cur) * 2
+	for target < maxAutoShardCnt && uint64(s.Len()) > target*uint64(s.maxLoad)/2 {
+		target *= 2
+	}
+	s.resizeLocked(target)
+}
+
+// overloaded 是否存在元素个数超过 maxLoad 的分片
+func (s *ShardMap[K, V]) overloaded() bool {
+	t := s.table.Load()
+	if uint64(len(t.shards)) >= s.limitShardCount(maxAutoShardCnt) {
+		return false
+	}
+	for _, sh := range t.shards {
+		sh.mu.RLock()
+		n := len(sh.container)
+		sh.mu.RUnlock()
+		if n > s.maxLoad {
+			return true
+		}
+	}
+	return false
+}
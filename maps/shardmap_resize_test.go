@@ -0,0 +1,208 @@
+package maps
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardMapResize(t *testing.T) {
+	testCase := []struct {
+		name      string
+		from      uint64
+		to        uint64
+		wantCount int
+	}{
+		{
+			name:      "grow",
+			from:      4,
+			to:        64,
+			wantCount: 64,
+		},
+		{
+			name:      "shrink",
+			from:      64,
+			to:        2,
+			wantCount: 2,
+		},
+		{
+			name:      "round up to power of 2",
+			from:      4,
+			to:        33,
+			wantCount: 64,
+		},
+		{
+			name:      "zero do nothing",
+			from:      4,
+			to:        0,
+			wantCount: 4,
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewShardMap[int, int](WithShardCount(tc.from))
+			for i := 0; i < 1000; i++ {
+				m.Set(i, i)
+			}
+			m.Resize(tc.to)
+
+			assert.Equal(t, tc.wantCount, m.ShardCount())
+			assert.Equal(t, 1000, m.Len())
+			for i := 0; i < 1000; i++ {
+				val, ok := m.Get(i)
+				assert.True(t, ok)
+				assert.Equal(t, i, val)
+			}
+		})
+	}
+}
+
+func TestShardMapResizeConcurrentWrite(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(2))
+	const writers, perWriter = 8, 2000
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				key := w*perWriter + i
+				m.Set(key, key)
+				m.Compute(key, func(old int, ok bool) (int, bool) {
+					return old + 1, true
+				})
+			}
+		}(w)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, count := range []uint64{4, 32, 8, 128, 16} {
+			m.Resize(count)
+		}
+	}()
+	wg.Wait()
+	<-done
+
+	assert.Equal(t, writers*perWriter, m.Len())
+	for key := 0; key < writers*perWriter; key++ {
+		val, ok := m.Get(key)
+		assert.True(t, ok)
+		assert.Equal(t, key+1, val)
+	}
+}
+
+func TestShardMapResizeDuringIteration(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(8))
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+
+	seen := make(map[int]int)
+	var resized int
+	for k := range m.All() {
+		seen[k]++
+		// 遍历过程中扩容和缩容
+		switch len(seen) {
+		case 100:
+			m.Resize(64)
+			resized++
+		case 500:
+			m.Resize(2)
+			resized++
+		}
+	}
+
+	assert.Equal(t, 2, resized)
+	assert.Equal(t, 1000, len(seen))
+	for k, cnt := range seen {
+		assert.Equal(t, 1, cnt, "key %d visited %d times", k, cnt)
+	}
+}
+
+func TestShardMapResizeKeepTTL(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(2))
+	m.StartJanitor(5 * time.Millisecond)
+	defer m.StopJanitor()
+
+	m.SetWithTTL(1, 1, 20*time.Millisecond)
+	m.Set(2, 2)
+	m.Resize(16)
+
+	_, ttl, ok := m.GetWithTTL(1)
+	assert.True(t, ok)
+	assert.True(t, ttl > 0)
+
+	// janitor 在 Resize 后按新的分片重启
+	assert.Eventually(t, func() bool {
+		return m.Len() == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestShardMapResizeExpired(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Open[int, int](dir, WithShardCount(2), WithSyncPolicy(SyncAlways))
+	assert.NoError(t, err)
+	defer m.Close()
+	w := m.Watch(1)
+	defer w.Close()
+
+	m.SetWithTTL(1, 1, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	m.Resize(8)
+	assert.Equal(t, 0, m.Len())
+
+	// 迁移时清理的 key 与惰性删除一样发送 EventExpire
+	assert.Equal(t, EventSet, (<-w.Events()).Type)
+	assert.Equal(t, Event[int, int]{Type: EventExpire, Key: 1, Old: 1, HasOld: true}, <-w.Events())
+
+	// 并写入 WAL 删除记录
+	var ops []walOp
+	assert.NoError(t, replayWAL(walPath(dir, m.wal.gen), func(rec *walRecord[int, int]) {
+		ops = append(ops, rec.Op)
+	}))
+	assert.Equal(t, []walOp{walSet, walDelete}, ops)
+}
+
+func TestShardMapResizeEviction(t *testing.T) {
+	var evicted int
+	m := NewShardMap[int, int](
+		WithShardCount(1),
+		WithCapacity(8),
+		WithOnEvict(func(key int, val int) { evicted++ }),
+	)
+	for i := 0; i < 8; i++ {
+		m.Set(i, i)
+	}
+	m.Resize(8)
+
+	// 每个分片容量为 1，迁移时超出的元素被淘汰
+	assert.Equal(t, 8-m.Len(), evicted)
+	assert.LessOrEqual(t, m.Len(), 8)
+	for i := 100; i < 200; i++ {
+		m.Set(i, i)
+	}
+	assert.LessOrEqual(t, m.Len(), 8)
+}
+
+func TestShardMapAutoGrow(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(1), WithAutoGrow(64))
+	for i := 0; i < 10000; i++ {
+		m.Set(i, i)
+	}
+
+	assert.Eventually(t, func() bool {
+		return !m.growing.Load() && !m.overloaded()
+	}, time.Second, time.Millisecond)
+	assert.GreaterOrEqual(t, m.ShardCount(), 128)
+	assert.Equal(t, 10000, m.Len())
+	for i := 0; i < 10000; i++ {
+		_, ok := m.Get(i)
+		assert.True(t, ok)
+	}
+}
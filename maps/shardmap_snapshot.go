@@ -0,0 +1,212 @@
+package maps
+
+import (
+	"iter"
+	"time"
+)
+
+// Snapshot ShardMap 或 RCUMap 在某一时刻的只读副本，所有分片（桶）在同一时刻被复制，
+// 之后的修改不会影响 Snapshot。已过期的 key 在复制时被过滤
+type Snapshot[K comparable, V any] struct {
+	items map[K]V
+}
+
+// Snapshot 锁住所有分片后复制全部数据，返回跨分片一致的只读视图。
+// 复制期间所有写入都会被阻塞，数据量较大时应避免频繁调用
+func (s *ShardMap[K, V]) Snapshot() *Snapshot[K, V] {
+	t := s.lockAllShards()
+	defer s.unlockAllShards(t)
+
+	now := time.Now().UnixNano()
+	items := make(map[K]V, s.Len())
+	for _, sh := range t.shards {
+		for k, v := range sh.container {
+			if !sh.expired(k, now) {
+				items[k] = v
+			}
+		}
+	}
+	return &Snapshot[K, V]{items: items}
+}
+
+// Get 获取值
+func (sn *Snapshot[K, V]) Get(key K) (V, bool) {
+	val, ok := sn.items[key]
+	return val, ok
+}
+
+// Len 返回元素个数
+func (sn *Snapshot[K, V]) Len() int {
+	return len(sn.items)
+}
+
+// Keys 返回所有 key
+func (sn *Snapshot[K, V]) Keys() []K {
+	keys := make([]K, 0, len(sn.items))
+	for k := range sn.items {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values 返回所有 value
+func (sn *Snapshot[K, V]) Values() []V {
+	values := make([]V, 0, len(sn.items))
+	for _, v := range sn.items {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Range 遍历，f 返回 true 时停止
+func (sn *Snapshot[K, V]) Range(f func(key K, value V) (stop bool)) {
+	for k, v := range sn.items {
+		if f(k, v) {
+			return
+		}
+	}
+}
+
+// All 返回遍历所有键值对的迭代器
+func (sn *Snapshot[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range sn.items {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// KeysSeq 返回遍历所有 key 的迭代器
+func (sn *Snapshot[K, V]) KeysSeq() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range sn.items {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesSeq 返回遍历所有 value 的迭代器
+func (sn *Snapshot[K, V]) ValuesSeq() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range sn.items {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Clone 返回一个相同配置（分片数、哈希函数、容量与淘汰策略等）的新 ShardMap，包含当前所有数据及其 TTL。
+// 新 ShardMap 不会持久化，也不会继承 janitor 和订阅；淘汰策略的访问顺序不会被复制
+func (s *ShardMap[K, V]) Clone() *ShardMap[K, V] {
+	t := s.lockAllShards()
+	now := time.Now().UnixNano()
+	buf := make([]entry[K, V], 0, s.Len())
+	for _, sh := range t.shards {
+		for k, v := range sh.container {
+			if !sh.expired(k, now) {
+				buf = append(buf, entry[K, V]{key: k, val: v, expireAt: sh.expires[k]})
+			}
+		}
+	}
+	s.unlockAllShards(t)
+
+	c := &ShardMap[K, V]{
+		hasher:    s.hasher,
+		onEvict:   s.onEvict,
+		newPolicy: s.newPolicy,
+		capacity:  s.capacity,
+		maxLoad:   s.maxLoad,
+
+		statsEnabled: s.statsEnabled,
+	}
+	count := uint64(len(t.shards))
+	c.table.Store(c.newTable(count, len(buf)/int(count)))
+
+	// 持有 resizeMu，避免写入过程中触发的自动扩容与写入并发
+	c.resizeMu.Lock()
+	var evicted []entry[K, V]
+	nt := c.table.Load()
+	for _, e := range buf {
+		if ev, ok := c.setLocked(nt.shards[c.hasher(e.key)&nt.mask], e.key, e.val, e.expireAt); ok {
+			evicted = append(evicted, ev)
+		}
+	}
+	c.resizeMu.Unlock()
+	for _, e := range evicted {
+		c.notifyEvict(e, true)
+	}
+	return c
+}
+
+// Clear 原子地删除所有元素，订阅者会收到每个 key 的删除事件
+func (s *ShardMap[K, V]) Clear() {
+	t := s.lockAllShards()
+	defer s.unlockAllShards(t)
+
+	s.clearLocked(t)
+	if s.wal != nil {
+		s.wal.append(walRecord[K, V]{Op: walClear})
+	}
+}
+
+// clearLocked 清空所有分片，调用方需通过 lockAllShards 锁住所有分片
+func (s *ShardMap[K, V]) clearLocked(t *shardTable[K, V]) {
+	for _, sh := range t.shards {
+		if s.watchers.active() {
+			for k, v := range sh.container {
+				s.watchers.emit(Event[K, V]{Type: EventDelete, Key: k, Old: v, HasOld: true})
+			}
+		}
+		s.total.Add(-uint64(len(sh.container)))
+		sh.version++
+		clear(sh.container)
+		clear(sh.expires)
+		if s.newPolicy != nil {
+			sh.policy = s.newPolicy()
+		}
+	}
+}
+
+// ExactLen 锁住所有分片后统计未过期的元素个数，与同一时刻的 Snapshot().Len() 一致。
+// 与 Len 相比代价较高，Len 包含尚未清理的过期元素
+func (s *ShardMap[K, V]) ExactLen() int {
+	t := s.lockAllShards()
+	defer s.unlockAllShards(t)
+
+	now := time.Now().UnixNano()
+	n := 0
+	for _, sh := range t.shards {
+		if len(sh.expires) == 0 {
+			n += len(sh.container)
+			continue
+		}
+		for k := range sh.container {
+			if !sh.expired(k, now) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// lockAllShards 锁住 resizeMu 及所有分片，保证期间分片表不会被替换，需使用 unlockAllShards 解锁
+func (s *ShardMap[K, V]) lockAllShards() *shardTable[K, V] {
+	s.resizeMu.Lock()
+	t := s.table.Load()
+	for _, sh := range t.shards {
+		s.lockTimed(sh)
+	}
+	return t
+}
+
+func (s *ShardMap[K, V]) unlockAllShards(t *shardTable[K, V]) {
+	for _, sh := range t.shards {
+		sh.mu.Unlock()
+	}
+	s.resizeMu.Unlock()
+}
@@ -0,0 +1,156 @@
+package maps
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardMapSnapshot(t *testing.T) {
+	m := NewShardMap[string, int](WithShardCount(4))
+	m.SetMany(map[string]int{"a": 1, "b": 2, "c": 3})
+	m.SetWithTTL("d", 4, 10*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+
+	sn := m.Snapshot()
+	m.Set("a", 100)
+	m.Delete("b")
+	m.Set("e", 5)
+
+	// 之后的修改不影响快照，已过期的 key 不在快照中
+	assert.Equal(t, 3, sn.Len())
+	val, ok := sn.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+	_, ok = sn.Get("d")
+	assert.False(t, ok)
+
+	keys := sn.Keys()
+	sort.Strings(keys)
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+	values := sn.Values()
+	sort.Ints(values)
+	assert.Equal(t, []int{1, 2, 3}, values)
+
+	sum := 0
+	for _, v := range sn.All() {
+		sum += v
+	}
+	assert.Equal(t, 6, sum)
+}
+
+func TestShardMapSnapshotConsistent(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(16))
+	const n = 64
+	for i := 0; i < n; i++ {
+		m.Set(i, 0)
+	}
+
+	// SetManyAtomic 保证所有 key 同时更新，任何时刻的快照中所有值都应相同
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		keys := make([]int, n)
+		for i := range keys {
+			keys[i] = i
+		}
+		for round := 1; round <= 200; round++ {
+			items := make(map[int]int, n)
+			for _, k := range keys {
+				items[k] = round
+			}
+			m.SetManyAtomic(items)
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		sn := m.Snapshot()
+		assert.Equal(t, n, sn.Len())
+		first, _ := sn.Get(0)
+		for _, v := range sn.All() {
+			assert.Equal(t, first, v)
+		}
+	}
+	wg.Wait()
+}
+
+func TestShardMapClone(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(8), WithCapacity(80))
+	for i := 0; i < 10; i++ {
+		m.Set(i, i)
+	}
+	m.SetWithTTL(100, 100, time.Minute)
+
+	c := m.Clone()
+	m.Set(0, -1)
+	c.Delete(1)
+
+	assert.Equal(t, 8, c.ShardCount())
+	assert.Equal(t, 10, c.Len())
+	val, _ := c.Get(0)
+	assert.Equal(t, 0, val)
+	_, ttl, ok := c.GetWithTTL(100)
+	assert.True(t, ok)
+	assert.True(t, ttl > 0)
+	_, ok = m.Get(1)
+	assert.True(t, ok)
+
+	// 容量限制同样被复制
+	for i := 1000; i < 2000; i++ {
+		c.Set(i, i)
+	}
+	assert.LessOrEqual(t, c.Len(), 80)
+}
+
+func TestShardMapClear(t *testing.T) {
+	m := NewShardMap[string, int](WithShardCount(4), WithCapacity(8))
+	m.SetMany(map[string]int{"a": 1, "b": 2, "c": 3})
+	w := m.Watch("a")
+	defer w.Close()
+
+	m.Clear()
+	assert.Equal(t, 0, m.Len())
+	assert.Equal(t, 0, m.ExactLen())
+	_, ok := m.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, Event[string, int]{Type: EventDelete, Key: "a", Old: 1, HasOld: true}, <-w.Events())
+
+	// 淘汰策略被重置，清空后可以继续写满
+	for i := 0; i < 8; i++ {
+		m.Set(string(rune('a'+i)), i)
+	}
+	assert.LessOrEqual(t, m.Len(), 8)
+}
+
+func TestShardMapExactLen(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(4))
+	for i := 0; i < 10; i++ {
+		m.Set(i, i)
+	}
+	m.SetWithTTL(100, 100, 10*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+
+	// Len 包含尚未清理的过期元素，ExactLen 不包含
+	assert.Equal(t, 11, m.Len())
+	assert.Equal(t, 10, m.ExactLen())
+	assert.Equal(t, m.ExactLen(), m.Snapshot().Len())
+}
+
+func TestShardMapPersistClear(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Open[string, int](dir)
+	assert.NoError(t, err)
+	m.Set("a", 1)
+	m.Clear()
+	m.Set("b", 2)
+	assert.NoError(t, m.Close())
+
+	m, err = Open[string, int](dir)
+	assert.NoError(t, err)
+	defer m.Close()
+	assert.Equal(t, map[string]int{"b": 2}, m.Snapshot().items)
+}
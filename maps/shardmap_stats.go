@@ -0,0 +1,116 @@
+package maps
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// ShardStats 单个分片的统计信息
+type ShardStats struct {
+	// Entries 分片中的元素个数（包括已过期但尚未清理的 key）
+	Entries int    `json:"entries"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	// Locks 加锁次数，LockWait 等待锁的累计时间
+	Locks    uint64        `json:"locks"`
+	LockWait time.Duration `json:"lock_wait_ns"`
+}
+
+// Stats ShardMap 的统计信息。命中率和锁等待时间需要通过 WithStats 开启，
+// 汇总值包括 Resize 之前旧分片的累计数据
+type Stats struct {
+	Shards   []ShardStats  `json:"shards"`
+	Entries  int           `json:"entries"`
+	Hits     uint64        `json:"hits"`
+	Misses   uint64        `json:"misses"`
+	Locks    uint64        `json:"locks"`
+	LockWait time.Duration `json:"lock_wait_ns"`
+}
+
+// HitRatio 命中率，没有查询时返回 0
+func (st Stats) HitRatio() float64 {
+	total := st.Hits + st.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(st.Hits) / float64(total)
+}
+
+// Stats 返回各分片的元素个数、命中次数与锁等待时间
+func (s *ShardMap[K, V]) Stats() Stats {
+	t := s.table.Load()
+	st := Stats{
+		Shards:   make([]ShardStats, len(t.shards)),
+		Hits:     s.retired.hits.Load(),
+		Misses:   s.retired.misses.Load(),
+		Locks:    s.retired.locks.Load(),
+		LockWait: time.Duration(s.retired.lockWait.Load()),
+	}
+	for i, sh := range t.shards {
+		sh.mu.RLock()
+		entries := len(sh.container)
+		sh.mu.RUnlock()
+
+		ss := ShardStats{
+			Entries:  entries,
+			Hits:     sh.stats.hits.Load(),
+			Misses:   sh.stats.misses.Load(),
+			Locks:    sh.stats.locks.Load(),
+			LockWait: time.Duration(sh.stats.lockWait.Load()),
+		}
+		st.Shards[i] = ss
+		st.Entries += ss.Entries
+		st.Hits += ss.Hits
+		st.Misses += ss.Misses
+		st.Locks += ss.Locks
+		st.LockWait += ss.LockWait
+	}
+	return st
+}
+
+// ExpvarFunc 将 Stats 适配为 expvar.Var，每次读取时重新统计
+func (s *ShardMap[K, V]) ExpvarFunc() expvar.Func {
+	return func() any {
+		return s.Stats()
+	}
+}
+
+// PublishExpvar 以 name 发布到 expvar（/debug/vars），与 expvar.Publish 一样，name 重复时会 panic
+func (s *ShardMap[K, V]) PublishExpvar(name string) {
+	expvar.Publish(name, s.ExpvarFunc())
+}
+
+// recordLookup 记录一次查询是否命中
+func (s *ShardMap[K, V]) recordLookup(sh *shard[K, V], hit bool) {
+	if !s.statsEnabled {
+		return
+	}
+	if hit {
+		sh.stats.hits.Add(1)
+	} else {
+		sh.stats.misses.Add(1)
+	}
+}
+
+// shardCounters 分片的统计计数器，均为原子操作
+type shardCounters struct {
+	hits     atomic.Uint64
+	misses   atomic.Uint64
+	locks    atomic.Uint64
+	lockWait atomic.Int64
+}
+
+// recordWait 记录一次加锁及其等待时间
+func (c *shardCounters) recordWait(start time.Time) {
+	c.locks.Add(1)
+	c.lockWait.Add(int64(time.Since(start)))
+}
+
+// add 累加 other 的计数
+func (c *shardCounters) add(other *shardCounters) {
+	c.hits.Add(other.hits.Load())
+	c.misses.Add(other.misses.Load())
+	c.locks.Add(other.locks.Load())
+	c.lockWait.Add(other.lockWait.Load())
+}
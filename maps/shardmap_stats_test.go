@@ -0,0 +1,80 @@
+package maps
+
+import (
+	"encoding/json"
+	"expvar"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardMapStats(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(4), WithStats())
+	for i := 0; i < 100; i++ {
+		m.Set(i, i)
+	}
+	for i := 0; i < 150; i++ {
+		m.Get(i)
+	}
+
+	st := m.Stats()
+	assert.Equal(t, 4, len(st.Shards))
+	assert.Equal(t, 100, st.Entries)
+	assert.Equal(t, uint64(100), st.Hits)
+	assert.Equal(t, uint64(50), st.Misses)
+	assert.Equal(t, uint64(250), st.Locks)
+	assert.InDelta(t, 100.0/150.0, st.HitRatio(), 1e-9)
+
+	var entries int
+	for _, ss := range st.Shards {
+		entries += ss.Entries
+	}
+	assert.Equal(t, st.Entries, entries)
+}
+
+func TestShardMapStatsDisabled(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(4))
+	m.Set(1, 1)
+	m.Get(1)
+	m.Get(2)
+
+	st := m.Stats()
+	assert.Equal(t, 1, st.Entries)
+	assert.Equal(t, uint64(0), st.Hits+st.Misses+st.Locks)
+	assert.Equal(t, 0.0, st.HitRatio())
+}
+
+func TestShardMapStatsAfterResize(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(2), WithStats())
+	m.Set(1, 1)
+	m.Get(1)
+	m.Resize(8)
+	m.Get(2)
+
+	st := m.Stats()
+	assert.Equal(t, 8, len(st.Shards))
+	assert.Equal(t, uint64(1), st.Hits)
+	assert.Equal(t, uint64(1), st.Misses)
+}
+
+// expvarSeq 保证 go test -count=N 时每次发布的名字不重复
+var expvarSeq int
+
+func TestShardMapExpvar(t *testing.T) {
+	expvarSeq++
+	name := "gokit_shardmap_stats_test_" + strconv.Itoa(expvarSeq)
+	m := NewShardMap[string, int](WithShardCount(2), WithStats())
+	m.PublishExpvar(name)
+	m.Set("a", 1)
+	m.Get("a")
+
+	v := expvar.Get(name)
+	assert.NotNil(t, v)
+
+	var st Stats
+	assert.NoError(t, json.Unmarshal([]byte(v.String()), &st))
+	assert.Equal(t, 1, st.Entries)
+	assert.Equal(t, uint64(1), st.Hits)
+	assert.Equal(t, 2, len(st.Shards))
+}
@@ -10,7 +10,7 @@ import (
 
 // benchmark helper
 func benchShardMapSet(b *testing.B, goroutines int) {
-	m := NewShardMap[string, int](16)
+	m := NewShardMap[string, int](WithShardCount(16))
 
 	b.SetParallelism(goroutines)
 	b.ResetTimer()
@@ -25,7 +25,7 @@ func benchShardMapSet(b *testing.B, goroutines int) {
 }
 
 func benchShardMapGet(b *testing.B, goroutines int) {
-	m := NewShardMap[string, int](16)
+	m := NewShardMap[string, int](WithShardCount(16))
 	for i := 0; i < 1_000_000; i++ {
 		m.Set("k"+strconv.Itoa(i), i)
 	}
@@ -43,7 +43,7 @@ func benchShardMapGet(b *testing.B, goroutines int) {
 }
 
 func benchShardMapMix(b *testing.B, goroutines int) {
-	m := NewShardMap[string, int](16)
+	m := NewShardMap[string, int](WithShardCount(16))
 
 	b.SetParallelism(goroutines)
 	b.ResetTimer()
@@ -0,0 +1,168 @@
+package maps
+
+import (
+	"sync"
+	"time"
+)
+
+// SetWithTTL 设置 key, value，并在 ttl 之后过期；ttl <= 0 时等同于 Set
+func (s *ShardMap[K, V]) SetWithTTL(key K, val V, ttl time.Duration) {
+	var expireAt int64
+	if ttl > 0 {
+		expireAt = time.Now().Add(ttl).UnixNano()
+	}
+	shardMap := s.lockShard(key)
+	evicted, ok := s.setLocked(shardMap, key, val, expireAt)
+	shardMap.mu.Unlock()
+	s.notifyEvict(evicted, ok)
+}
+
+// GetWithTTL 获取值以及剩余存活时间，未设置过期时间的 key 剩余时间为 0
+func (s *ShardMap[K, V]) GetWithTTL(key K) (V, time.Duration, bool) {
+	now := time.Now().UnixNano()
+	shardMap := s.rlockShard(key)
+	val, ok := shardMap.container[key]
+	if !ok {
+		s.recordLookup(shardMap, false)
+		shardMap.unlockRead()
+		return val, 0, false
+	}
+	expireAt, hasTTL := shardMap.expires[key]
+	alive := !hasTTL || now < expireAt
+	s.recordLookup(shardMap, alive)
+	if alive {
+		shardMap.touchLocked(key)
+	}
+	shardMap.unlockRead()
+
+	if !hasTTL {
+		return val, 0, true
+	}
+	if now >= expireAt {
+		s.expireKey(key)
+		var zero V
+		return zero, 0, false
+	}
+	return val, time.Duration(expireAt - now), true
+}
+
+// StartJanitor 为每个分片启动一个后台清理协程，每隔 interval 清理一次该分片中已过期的 key。
+// 重复调用会先停止之前的清理协程
+func (s *ShardMap[K, V]) StartJanitor(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	s.janitorMu.Lock()
+	defer s.janitorMu.Unlock()
+
+	s.startJanitorLocked(interval)
+}
+
+// StopJanitor 停止后台清理协程，并等待其全部退出
+func (s *ShardMap[K, V]) StopJanitor() {
+	s.janitorMu.Lock()
+	defer s.janitorMu.Unlock()
+
+	if s.janitor != nil {
+		s.janitor.stopAndWait()
+		s.janitor = nil
+	}
+}
+
+// startJanitorLocked 按当前分片表启动清理协程，调用方需持有 janitorMu
+func (s *ShardMap[K, V]) startJanitorLocked(interval time.Duration) {
+	if s.janitor != nil {
+		s.janitor.stopAndWait()
+	}
+	shards := s.table.Load().shards
+	j := &janitor{interval: interval, stop: make(chan struct{})}
+	for i, sh := range shards {
+		// 错开各分片的清理时间，避免所有分片同时加锁
+		offset := interval * time.Duration(i) / time.Duration(len(shards))
+		j.wg.Add(1)
+		go j.run(interval, offset, func() { s.sweep(sh) })
+	}
+	s.janitor = j
+}
+
+// restartJanitor Resize 后按新的分片表重启清理协程
+func (s *ShardMap[K, V]) restartJanitor() {
+	s.janitorMu.Lock()
+	defer s.janitorMu.Unlock()
+
+	if s.janitor != nil {
+		s.startJanitorLocked(s.janitor.interval)
+	}
+}
+
+// expired 判断 key 是否已过期，调用方需持有 sh 的读锁或写锁
+func (sh *shard[K, V]) expired(key K, now int64) bool {
+	if len(sh.expires) == 0 {
+		return false
+	}
+	expireAt, ok := sh.expires[key]
+	return ok && now >= expireAt
+}
+
+// expireKey 加写锁后再次确认 key 已过期，然后将其删除
+func (s *ShardMap[K, V]) expireKey(key K) {
+	sh := s.lockShard(key)
+	if sh.expired(key, time.Now().UnixNano()) {
+		s.expireLocked(sh, key)
+	}
+	sh.mu.Unlock()
+}
+
+// sweep 清理分片中所有已过期的 key
+func (s *ShardMap[K, V]) sweep(sh *shard[K, V]) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	// 分片已被迁移，由 Resize 后重启的清理协程负责新分片
+	if sh.migrated {
+		return
+	}
+	now := time.Now().UnixNano()
+	for key, expireAt := range sh.expires {
+		if now >= expireAt {
+			s.expireLocked(sh, key)
+		}
+	}
+}
+
+// janitor 管理后台清理协程的生命周期
+type janitor struct {
+	interval time.Duration
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func (j *janitor) run(interval, offset time.Duration, sweep func()) {
+	defer j.wg.Done()
+
+	if offset > 0 {
+		timer := time.NewTimer(offset)
+		select {
+		case <-j.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+			sweep()
+		}
+	}
+}
+
+func (j *janitor) stopAndWait() {
+	close(j.stop)
+	j.wg.Wait()
+}
@@ -0,0 +1,118 @@
+package maps
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardMapTTL(t *testing.T) {
+	testCase := []struct {
+		name     string
+		ttl      time.Duration
+		wait     time.Duration
+		wantOk   bool
+		wantLen  int
+		checkTTL bool
+	}{
+		{
+			name:     "not expired",
+			ttl:      time.Minute,
+			wantOk:   true,
+			wantLen:  1,
+			checkTTL: true,
+		},
+		{
+			name:    "expired",
+			ttl:     10 * time.Millisecond,
+			wait:    30 * time.Millisecond,
+			wantOk:  false,
+			wantLen: 0,
+		},
+		{
+			name:    "ttl <= 0 never expire",
+			ttl:     0,
+			wantOk:  true,
+			wantLen: 1,
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewShardMap[string, int](WithShardCount(4))
+			m.SetWithTTL("a", 1, tc.ttl)
+			time.Sleep(tc.wait)
+
+			val, ttl, ok := m.GetWithTTL("a")
+			assert.Equal(t, tc.wantOk, ok)
+			if ok {
+				assert.Equal(t, 1, val)
+			}
+			if tc.checkTTL {
+				assert.True(t, ttl > 0 && ttl <= tc.ttl)
+			}
+			assert.Equal(t, tc.wantLen, m.Len())
+		})
+	}
+}
+
+func TestShardMapLazyExpire(t *testing.T) {
+	m := NewShardMap[string, int](WithShardCount(4))
+	m.SetWithTTL("a", 1, 10*time.Millisecond)
+	m.Set("b", 2)
+	time.Sleep(30 * time.Millisecond)
+
+	// Keys/Values/Range 不会返回已过期的 key
+	assert.Equal(t, []string{"b"}, m.Keys())
+	assert.Equal(t, []int{2}, m.Values())
+	assert.Equal(t, 2, m.Len())
+
+	_, ok := m.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, m.Len())
+}
+
+func TestShardMapSetClearTTL(t *testing.T) {
+	m := NewShardMap[string, int](WithShardCount(4))
+	m.SetWithTTL("a", 1, 10*time.Millisecond)
+	m.Set("a", 2)
+	time.Sleep(30 * time.Millisecond)
+
+	val, ttl, ok := m.GetWithTTL("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, val)
+	assert.Equal(t, time.Duration(0), ttl)
+}
+
+func TestShardMapJanitor(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(4))
+	for i := 0; i < 100; i++ {
+		m.SetWithTTL(i, i, 10*time.Millisecond)
+	}
+	m.Set(100, 100)
+
+	m.StartJanitor(5 * time.Millisecond)
+	defer m.StopJanitor()
+
+	assert.Eventually(t, func() bool {
+		return m.Len() == 1
+	}, time.Second, 5*time.Millisecond)
+
+	val, ok := m.Get(100)
+	assert.True(t, ok)
+	assert.Equal(t, 100, val)
+}
+
+func TestShardMapStopJanitor(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(4))
+	m.StartJanitor(time.Millisecond)
+	m.StartJanitor(time.Millisecond)
+	m.StopJanitor()
+	m.StopJanitor()
+
+	m.SetWithTTL(1, 1, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	// janitor 已停止，过期 key 仍在计数中，直到被访问
+	assert.Equal(t, 1, m.Len())
+}
@@ -0,0 +1,136 @@
+package maps
+
+import (
+	"errors"
+	"runtime"
+	"time"
+)
+
+// maxTxnRetries 提交冲突时最多重新执行回调的次数
+const maxTxnRetries = 16
+
+var ErrTxnConflict = errors.New("maps: transaction conflict, retries exhausted")
+
+// Tx 事务，只能在 Txn 的回调中使用。
+// 读取时记录分片的版本，写入先缓存在事务内（Get 能读到本事务的写入），提交时统一生效
+type Tx[K comparable, V any] struct {
+	s *ShardMap[K, V]
+	// reads 记录读过的 key 所在的分片，versions 记录分片第一次被读到时的版本
+	reads    map[K]*shard[K, V]
+	versions map[*shard[K, V]]uint64
+	writes   map[K]txWrite[V]
+	// order 按写入顺序记录 key，提交时按该顺序生效
+	order []K
+}
+
+type txWrite[V any] struct {
+	val     V
+	deleted bool
+}
+
+// Get 读取 key，优先返回本事务中的写入
+func (tx *Tx[K, V]) Get(key K) (V, bool) {
+	if w, ok := tx.writes[key]; ok {
+		return w.val, !w.deleted
+	}
+
+	s := tx.s
+	sh := s.rlockShard(key)
+	val, ok := sh.container[key]
+	if ok && sh.expired(key, time.Now().UnixNano()) {
+		var zero V
+		val, ok = zero, false
+	}
+	if _, seen := tx.versions[sh]; !seen {
+		tx.versions[sh] = sh.version
+	}
+	sh.unlockRead()
+
+	tx.reads[key] = sh
+	return val, ok
+}
+
+// Set 在事务中写入 key，提交后清除 key 原有的 TTL
+func (tx *Tx[K, V]) Set(key K, val V) {
+	tx.write(key, txWrite[V]{val: val})
+}
+
+// Delete 在事务中删除 key
+func (tx *Tx[K, V]) Delete(key K) {
+	tx.write(key, txWrite[V]{deleted: true})
+}
+
+func (tx *Tx[K, V]) write(key K, w txWrite[V]) {
+	if _, ok := tx.writes[key]; !ok {
+		tx.order = append(tx.order, key)
+	}
+	tx.writes[key] = w
+}
+
+// Txn 执行事务：fn 返回 nil 时原子地提交所有写入，返回错误时丢弃所有写入并返回该错误。
+// 采用乐观并发控制，提交时按分片下标顺序锁住涉及的分片，若读过的分片在此期间被修改，
+// 则重新执行 fn，超过 maxTxnRetries 次后返回 ErrTxnConflict。因此 fn 可能被执行多次，不应有副作用，
+// 且 fn 中读到的数据只有在 Txn 返回 nil 时才保证是一致的。
+// 冲突按分片检测，同一分片中其他 key 的修改也会导致重试。
+// 持久化的 ShardMap 中，事务的写入逐条记录到 WAL，崩溃时可能只恢复部分写入
+func (s *ShardMap[K, V]) Txn(fn func(tx *Tx[K, V]) error) error {
+	for i := 0; i < maxTxnRetries; i++ {
+		tx := &Tx[K, V]{
+			s:        s,
+			reads:    make(map[K]*shard[K, V]),
+			versions: make(map[*shard[K, V]]uint64),
+			writes:   make(map[K]txWrite[V]),
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if tx.commit() {
+			return nil
+		}
+		runtime.Gosched()
+	}
+	return ErrTxnConflict
+}
+
+// commit 锁住读写涉及的分片，校验读过的分片未被修改后写入，校验失败时返回 false
+func (tx *Tx[K, V]) commit() bool {
+	if len(tx.writes) == 0 && len(tx.reads) == 0 {
+		return true
+	}
+	s := tx.s
+	keys := make([]K, 0, len(tx.reads)+len(tx.order))
+	for k := range tx.reads {
+		keys = append(keys, k)
+	}
+	keys = append(keys, tx.order...)
+
+	t, shards := s.lockKeys(keys)
+	for k, sh := range tx.reads {
+		// 分片被 Resize 迁移后 key 所在的分片会变化，同样视为冲突
+		if t.shards[s.hasher(k)&t.mask] != sh || sh.version != tx.versions[sh] {
+			unlockAll(shards)
+			return false
+		}
+	}
+
+	var evicted []entry[K, V]
+	for _, k := range tx.order {
+		sh := t.shards[s.hasher(k)&t.mask]
+		w := tx.writes[k]
+		if w.deleted {
+			if _, ok := sh.container[k]; ok {
+				s.removeLocked(sh, k)
+			}
+			continue
+		}
+		if e, ok := s.setLocked(sh, k, w.val, 0); ok {
+			evicted = append(evicted, e)
+		}
+	}
+	unlockAll(shards)
+
+	for _, e := range evicted {
+		s.notifyEvict(e, true)
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+package maps
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardMapTxn(t *testing.T) {
+	errAbort := errors.New("abort")
+	testCase := []struct {
+		name    string
+		fn      func(tx *Tx[string, int]) error
+		wantErr error
+		want    map[string]int
+	}{
+		{
+			name: "move",
+			fn: func(tx *Tx[string, int]) error {
+				val, _ := tx.Get("a")
+				tx.Delete("a")
+				tx.Set("b", val)
+				return nil
+			},
+			want: map[string]int{"b": 1, "c": 3},
+		},
+		{
+			name: "read your writes",
+			fn: func(tx *Tx[string, int]) error {
+				tx.Set("a", 10)
+				val, ok := tx.Get("a")
+				if !ok || val != 10 {
+					return errors.New("write not visible")
+				}
+				tx.Delete("c")
+				if _, ok := tx.Get("c"); ok {
+					return errors.New("delete not visible")
+				}
+				return nil
+			},
+			want: map[string]int{"a": 10, "b": 2},
+		},
+		{
+			name: "rollback",
+			fn: func(tx *Tx[string, int]) error {
+				tx.Set("a", 100)
+				tx.Delete("b")
+				return errAbort
+			},
+			wantErr: errAbort,
+			want:    map[string]int{"a": 1, "b": 2, "c": 3},
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewShardMap[string, int](WithShardCount(4))
+			m.SetMany(map[string]int{"a": 1, "b": 2, "c": 3})
+
+			err := m.Txn(tc.fn)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.want, m.GetMany([]string{"a", "b", "c"}))
+			assert.Equal(t, len(tc.want), m.Len())
+		})
+	}
+}
+
+func TestShardMapTxnConcurrentTransfer(t *testing.T) {
+	const accounts, initial = 10, 100
+	m := NewShardMap[int, int](WithShardCount(4))
+	for i := 0; i < accounts; i++ {
+		m.Set(i, initial)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				from, to := (g+i)%accounts, (g+i*7+1)%accounts
+				if from == to {
+					continue
+				}
+				err := m.Txn(func(tx *Tx[int, int]) error {
+					a, _ := tx.Get(from)
+					b, _ := tx.Get(to)
+					tx.Set(from, a-1)
+					tx.Set(to, b+1)
+					return nil
+				})
+				// 冲突重试耗尽时事务不生效，不影响总额
+				if err != nil {
+					assert.ErrorIs(t, err, ErrTxnConflict)
+				}
+			}
+		}(g)
+	}
+
+	// 转账过程中通过事务读取，提交成功时读到的总额始终不变
+	for i := 0; i < 100; i++ {
+		var sum int
+		err := m.Txn(func(tx *Tx[int, int]) error {
+			sum = 0
+			for k := 0; k < accounts; k++ {
+				v, _ := tx.Get(k)
+				sum += v
+			}
+			return nil
+		})
+		if err == nil {
+			assert.Equal(t, accounts*initial, sum)
+		}
+	}
+	wg.Wait()
+
+	sum := 0
+	for _, v := range m.All() {
+		sum += v
+	}
+	assert.Equal(t, accounts*initial, sum)
+}
+
+func TestShardMapTxnConflictRetry(t *testing.T) {
+	m := NewShardMap[string, int](WithShardCount(1))
+	m.Set("a", 1)
+
+	var calls int
+	err := m.Txn(func(tx *Tx[string, int]) error {
+		calls++
+		val, _ := tx.Get("a")
+		if calls == 1 {
+			// 读之后被其他写入修改，第一次提交失败
+			m.Set("a", 10)
+		}
+		tx.Set("a", val+1)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	val, _ := m.Get("a")
+	assert.Equal(t, 11, val)
+
+	err = m.Txn(func(tx *Tx[string, int]) error {
+		tx.Get("a")
+		m.Set("b", 1)
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrTxnConflict)
+}
+
+func TestShardMapTxnDuringResize(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(2))
+	m.Set(1, 1)
+	err := m.Txn(func(tx *Tx[int, int]) error {
+		val, _ := tx.Get(1)
+		if m.ShardCount() == 2 {
+			m.Resize(16)
+		}
+		tx.Set(2, val)
+		return nil
+	})
+	assert.NoError(t, err)
+	val, ok := m.Get(2)
+	assert.True(t, ok)
+	assert.Equal(t, 1, val)
+}
@@ -0,0 +1,358 @@
+package maps
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WAL 文件由若干帧组成，每帧为 [4 字节长度][4 字节 CRC32][payload]，
+// 每帧的 payload 是一个独立的 gob 流，对应一条 walRecord，这样编码可以在各个分片上并行进行。
+// 文件名为 wal-<gen>，gen 单调递增，每次压缩都会切换到新的 WAL 文件
+
+const (
+	walFilePrefix = "wal-"
+	frameHeader   = 8
+	// maxFrameSize 超过该长度的帧视为损坏
+	maxFrameSize = 64 << 20
+)
+
+type walOp uint8
+
+const (
+	walSet walOp = iota + 1
+	walDelete
+	walClear
+)
+
+// walRecord 一次 Set、Delete 或 Clear 操作，ExpireAt 为 0 表示永不过期
+type walRecord[K comparable, V any] struct {
+	Op       walOp
+	Key      K
+	Val      V
+	ExpireAt int64
+}
+
+var framePool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// wal 追加写日志，所有写入都在对应分片的写锁内进行，保证日志顺序与内存中的修改顺序一致。
+// 帧在 mu 之外编码，mu 只保护文件写入；fsync 在 mu 之外进行，并发的写入共用一次 fsync
+type wal[K comparable, V any] struct {
+	dir       string
+	policy    SyncPolicy
+	threshold uint64
+	compact   func() error
+
+	// syncMu 保证同一时刻只有一个 fsync，切换和关闭文件时也需要持有，锁顺序为 syncMu -> mu
+	syncMu sync.Mutex
+	// synced 已经落盘的记录序号，由 syncMu 保护
+	synced uint64
+
+	mu      sync.Mutex
+	f       *os.File
+	gen     uint64
+	records uint64
+	// written 已经写入文件的记录序号，只增不减
+	written uint64
+	// err 写入失败后不再继续写日志，由 Err/Sync/Close 返回
+	err error
+	// bgErr 后台压缩的错误，由 Sync/Close 返回后清空
+	bgErr error
+
+	// compactMu 保证同一时刻只有一个压缩任务，Close 时也会等待进行中的压缩
+	compactMu  sync.Mutex
+	compacting atomic.Bool
+	closed     atomic.Bool
+	stop       chan struct{}
+	// wg 跟踪后台 fsync 和压缩，Close 时等待它们退出
+	wg sync.WaitGroup
+}
+
+// openWAL 创建序号为 gen 的新 WAL 文件
+func openWAL[K comparable, V any](dir string, gen uint64, o *options, compact func() error) (*wal[K, V], error) {
+	w := &wal[K, V]{
+		dir:       dir,
+		policy:    o.syncPolicy,
+		threshold: o.compactThreshold,
+		compact:   compact,
+		stop:      make(chan struct{}),
+	}
+	if err := w.switchFile(gen); err != nil {
+		return nil, err
+	}
+	if w.policy == SyncEverySecond {
+		w.wg.Add(1)
+		go w.syncLoop()
+	}
+	return w, nil
+}
+
+// append 写入一条记录，调用方需持有 key 所在分片的写锁。
+// SyncAlways 时在 fsync 完成后返回，期间其他分片的写入可以继续并共用这次 fsync
+func (w *wal[K, V]) append(rec walRecord[K, V]) {
+	buf := framePool.Get().(*bytes.Buffer)
+	defer framePool.Put(buf)
+	buf.Reset()
+	var hdr [frameHeader]byte
+	buf.Write(hdr[:])
+	encErr := gob.NewEncoder(buf).Encode(&rec)
+	frame := buf.Bytes()
+	payload := frame[frameHeader:]
+	binary.LittleEndian.PutUint32(frame, uint32(len(payload)))
+	binary.LittleEndian.PutUint32(frame[4:], crc32.ChecksumIEEE(payload))
+
+	w.mu.Lock()
+	if w.err != nil {
+		w.mu.Unlock()
+		return
+	}
+	if encErr != nil {
+		w.err = encErr
+		w.mu.Unlock()
+		return
+	}
+	if _, err := w.f.Write(frame); err != nil {
+		w.err = err
+		w.mu.Unlock()
+		return
+	}
+	w.written++
+	seq := w.written
+	w.records++
+	if w.threshold > 0 && w.records >= w.threshold && !w.closed.Load() && w.compacting.CompareAndSwap(false, true) {
+		// 在 mu 内 Add，保证 close 将 closed 置为 true 之后不会再有新的压缩任务
+		w.wg.Add(1)
+		go w.backgroundCompact()
+	}
+	w.mu.Unlock()
+
+	if w.policy == SyncAlways {
+		w.fsync(seq)
+	}
+}
+
+func (w *wal[K, V]) backgroundCompact() {
+	defer w.wg.Done()
+	defer w.compacting.Store(false)
+
+	if err := w.compact(); err != nil {
+		w.mu.Lock()
+		w.bgErr = err
+		w.mu.Unlock()
+	}
+}
+
+// rotate 切换到下一个 WAL 文件并返回其序号，调用方需持有所有分片的写锁
+func (w *wal[K, V]) rotate() (uint64, error) {
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return 0, w.err
+	}
+	if err := w.f.Sync(); err != nil {
+		w.err = err
+		return 0, err
+	}
+	w.synced = w.written
+	if err := w.f.Close(); err != nil {
+		w.err = err
+		return 0, err
+	}
+	if err := w.switchFile(w.gen + 1); err != nil {
+		w.err = err
+		return 0, err
+	}
+	return w.gen, nil
+}
+
+// switchFile 创建新的 WAL 文件
+func (w *wal[K, V]) switchFile(gen uint64) error {
+	f, err := os.OpenFile(walPath(w.dir, gen), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	w.f = f
+	w.gen = gen
+	w.records = 0
+	return syncDir(w.dir)
+}
+
+// fsync 将序号不超过 seq 的记录落盘。fsync 期间不持有 mu，
+// 等待 syncMu 的调用方如果发现自己的记录已经被前一次 fsync 覆盖则直接返回
+func (w *wal[K, V]) fsync(seq uint64) {
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+
+	if w.synced >= seq {
+		return
+	}
+	w.mu.Lock()
+	f, target, failed := w.f, w.written, w.err != nil
+	w.mu.Unlock()
+	if failed || target == w.synced {
+		return
+	}
+	if err := f.Sync(); err != nil {
+		w.mu.Lock()
+		if w.err == nil {
+			w.err = err
+		}
+		w.mu.Unlock()
+		return
+	}
+	w.synced = target
+}
+
+// sync 立即 fsync，并返回之前发生的错误
+func (w *wal[K, V]) sync() error {
+	w.fsync(math.MaxUint64)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := errors.Join(w.err, w.bgErr)
+	w.bgErr = nil
+	return err
+}
+
+// error 返回之前发生的错误，不清空 bgErr
+func (w *wal[K, V]) error() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return errors.Join(w.err, w.bgErr)
+}
+
+func (w *wal[K, V]) syncLoop() {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.fsync(math.MaxUint64)
+		}
+	}
+}
+
+// close 等待后台任务和进行中的压缩结束，然后落盘并关闭文件，之后的写入不再记录日志
+func (w *wal[K, V]) close() error {
+	w.mu.Lock()
+	if !w.closed.CompareAndSwap(false, true) {
+		w.mu.Unlock()
+		return nil
+	}
+	w.mu.Unlock()
+	close(w.stop)
+	w.wg.Wait()
+
+	w.compactMu.Lock()
+	defer w.compactMu.Unlock()
+	w.fsync(math.MaxUint64)
+
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := errors.Join(w.err, w.bgErr, w.f.Close())
+	w.err = ErrClosed
+	w.bgErr = nil
+	return err
+}
+
+// replayWAL 依次回放 WAL 文件中的记录，遇到不完整或校验失败的帧时停止（通常是崩溃时未写完的尾部）
+func replayWAL[K comparable, V any](path string, apply func(rec *walRecord[K, V])) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		payload, ok := readFrame(r)
+		if !ok {
+			return nil
+		}
+		var rec walRecord[K, V]
+		if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&rec); err != nil {
+			return fmt.Errorf("maps: replay %s: %w", path, err)
+		}
+		apply(&rec)
+	}
+}
+
+// readFrame 读取并校验一帧，返回其 payload，遇到文件末尾或损坏的帧时返回 false
+func readFrame(r *bufio.Reader) ([]byte, bool) {
+	var hdr [frameHeader]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, false
+	}
+	size := binary.LittleEndian.Uint32(hdr[:4])
+	if size == 0 || size > maxFrameSize {
+		return nil, false
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, false
+	}
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(hdr[4:]) {
+		return nil, false
+	}
+	return payload, true
+}
+
+func walPath(dir string, gen uint64) string {
+	return filepath.Join(dir, walFilePrefix+strconv.FormatUint(gen, 10))
+}
+
+// listWAL 返回目录下所有 WAL 文件的序号，按从小到大排序
+func listWAL(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var gens []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, walFilePrefix) {
+			continue
+		}
+		gen, err := strconv.ParseUint(strings.TrimPrefix(name, walFilePrefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		gens = append(gens, gen)
+	}
+	sort.Slice(gens, func(i, j int) bool { return gens[i] < gens[j] })
+	return gens, nil
+}
+
+// syncDir fsync 目录，保证文件的创建与重命名落盘
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
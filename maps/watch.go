@@ -0,0 +1,258 @@
+package maps
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// EventType 变更事件类型
+type EventType uint8
+
+const (
+	// EventSet 写入或覆盖 key
+	EventSet EventType = iota + 1
+	// EventDelete 删除 key，包括因容量不足被淘汰
+	EventDelete
+	// EventExpire key 因 TTL 到期被删除
+	EventExpire
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventDelete:
+		return "delete"
+	case EventExpire:
+		return "expire"
+	default:
+		return "unknown"
+	}
+}
+
+// Event 一次变更，HasOld 为 false 表示 key 之前不存在；删除和过期事件的 New 为零值
+type Event[K comparable, V any] struct {
+	Type   EventType
+	Key    K
+	Old    V
+	HasOld bool
+	New    V
+}
+
+// Backpressure 订阅者的缓冲区满时的处理策略，两种策略都不会阻塞写入方
+type Backpressure uint8
+
+const (
+	// DropNewest 丢弃新事件（默认）
+	DropNewest Backpressure = iota
+	// DropOldest 丢弃缓冲区中最旧的事件，保证订阅者总能看到最新的变更
+	DropOldest
+)
+
+const defaultWatchBuffer = 64
+
+// WatchOption 用于配置订阅
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	buffer       int
+	backpressure Backpressure
+}
+
+// WithWatchBuffer 设置订阅的缓冲区大小，默认 64
+func WithWatchBuffer(n int) WatchOption {
+	return func(o *watchOptions) {
+		if n > 0 {
+			o.buffer = n
+		}
+	}
+}
+
+// WithBackpressure 设置缓冲区满时的处理策略，默认 DropNewest
+func WithBackpressure(policy Backpressure) WatchOption {
+	return func(o *watchOptions) {
+		o.backpressure = policy
+	}
+}
+
+// Watcher 一个订阅，事件在分片锁内按修改顺序投递，同一个 key 的事件顺序与修改顺序一致
+type Watcher[K comparable, V any] struct {
+	hub          *watchHub[K, V]
+	key          K
+	match        func(K) bool // 前缀订阅的匹配函数，为 nil 表示订阅单个 key
+	backpressure Backpressure
+	dropped      atomic.Uint64
+
+	mu     sync.Mutex
+	ch     chan Event[K, V]
+	closed bool
+}
+
+// Events 返回事件 channel，Close 后 channel 会被关闭
+func (w *Watcher[K, V]) Events() <-chan Event[K, V] {
+	return w.ch
+}
+
+// Dropped 返回因缓冲区满而丢弃的事件数
+func (w *Watcher[K, V]) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close 取消订阅并关闭事件 channel，可重复调用
+func (w *Watcher[K, V]) Close() {
+	w.hub.remove(w)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.ch)
+	}
+}
+
+// deliver 非阻塞地投递事件
+func (w *Watcher[K, V]) deliver(ev Event[K, V]) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+	select {
+	case w.ch <- ev:
+		return
+	default:
+	}
+	if w.backpressure == DropOldest {
+		select {
+		case <-w.ch:
+		default:
+		}
+		select {
+		case w.ch <- ev:
+		default:
+		}
+	}
+	w.dropped.Add(1)
+}
+
+// Watch 订阅单个 key 的变更
+func (s *ShardMap[K, V]) Watch(key K, opts ...WatchOption) *Watcher[K, V] {
+	return s.watchers.add(key, nil, opts)
+}
+
+// WatchFunc 订阅单个 key 的变更，fn 在独立的协程中按顺序执行，返回的 Watcher 仅用于 Close 和 Dropped
+func (s *ShardMap[K, V]) WatchFunc(key K, fn func(Event[K, V]), opts ...WatchOption) *Watcher[K, V] {
+	w := s.Watch(key, opts...)
+	go consume(w, fn)
+	return w
+}
+
+// WatchPrefix 订阅所有以 prefix 开头的 key 的变更
+func WatchPrefix[V any](m *ShardMap[string, V], prefix string, opts ...WatchOption) *Watcher[string, V] {
+	return m.watchers.add("", func(key string) bool {
+		return strings.HasPrefix(key, prefix)
+	}, opts)
+}
+
+// WatchPrefixFunc 同 WatchPrefix，事件交给 fn 在独立的协程中处理
+func WatchPrefixFunc[V any](m *ShardMap[string, V], prefix string, fn func(Event[string, V]), opts ...WatchOption) *Watcher[string, V] {
+	w := WatchPrefix(m, prefix, opts...)
+	go consume(w, fn)
+	return w
+}
+
+func consume[K comparable, V any](w *Watcher[K, V], fn func(Event[K, V])) {
+	for ev := range w.ch {
+		fn(ev)
+	}
+}
+
+// watchHub 管理 ShardMap 的所有订阅
+type watchHub[K comparable, V any] struct {
+	// count 为订阅总数，没有订阅时写入路径只有一次原子读
+	count    atomic.Int64
+	mu       sync.RWMutex
+	keys     map[K][]*Watcher[K, V]
+	prefixes []*Watcher[K, V]
+}
+
+func (h *watchHub[K, V]) add(key K, match func(K) bool, opts []WatchOption) *Watcher[K, V] {
+	o := &watchOptions{buffer: defaultWatchBuffer}
+	for _, opt := range opts {
+		opt(o)
+	}
+	w := &Watcher[K, V]{
+		hub:          h,
+		key:          key,
+		match:        match,
+		backpressure: o.backpressure,
+		ch:           make(chan Event[K, V], o.buffer),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if match != nil {
+		h.prefixes = append(h.prefixes, w)
+	} else {
+		if h.keys == nil {
+			h.keys = make(map[K][]*Watcher[K, V])
+		}
+		h.keys[key] = append(h.keys[key], w)
+	}
+	h.count.Add(1)
+	return w
+}
+
+func (h *watchHub[K, V]) remove(w *Watcher[K, V]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if w.match != nil {
+		if i := indexOf(h.prefixes, w); i >= 0 {
+			h.prefixes = append(h.prefixes[:i], h.prefixes[i+1:]...)
+			h.count.Add(-1)
+		}
+		return
+	}
+	subs := h.keys[w.key]
+	if i := indexOf(subs, w); i >= 0 {
+		subs = append(subs[:i], subs[i+1:]...)
+		if len(subs) == 0 {
+			delete(h.keys, w.key)
+		} else {
+			h.keys[w.key] = subs
+		}
+		h.count.Add(-1)
+	}
+}
+
+func indexOf[K comparable, V any](subs []*Watcher[K, V], w *Watcher[K, V]) int {
+	for i, sub := range subs {
+		if sub == w {
+			return i
+		}
+	}
+	return -1
+}
+
+// active 是否存在订阅
+func (h *watchHub[K, V]) active() bool {
+	return h.count.Load() > 0
+}
+
+// emit 向匹配的订阅投递事件，调用方需持有 key 所在分片的写锁
+func (h *watchHub[K, V]) emit(ev Event[K, V]) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, w := range h.keys[ev.Key] {
+		w.deliver(ev)
+	}
+	for _, w := range h.prefixes {
+		if w.match(ev.Key) {
+			w.deliver(ev)
+		}
+	}
+}
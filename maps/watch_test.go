@@ -0,0 +1,163 @@
+package maps
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardMapWatch(t *testing.T) {
+	m := NewShardMap[string, int](WithShardCount(4))
+	w := m.Watch("a")
+	defer w.Close()
+
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("a", 2)
+	m.Delete("a")
+	m.Delete("a")
+	m.SetWithTTL("a", 3, 10*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	m.Get("a")
+
+	want := []Event[string, int]{
+		{Type: EventSet, Key: "a", New: 1},
+		{Type: EventSet, Key: "a", Old: 1, HasOld: true, New: 2},
+		{Type: EventDelete, Key: "a", Old: 2, HasOld: true},
+		{Type: EventSet, Key: "a", New: 3},
+		{Type: EventExpire, Key: "a", Old: 3, HasOld: true},
+	}
+	for _, ev := range want {
+		assert.Equal(t, ev, <-w.Events())
+	}
+	assert.Equal(t, 0, len(w.Events()))
+}
+
+func TestWatchPrefix(t *testing.T) {
+	m := NewShardMap[string, int](WithShardCount(4))
+	w := WatchPrefix(m, "config/")
+	defer w.Close()
+
+	m.Set("config/a", 1)
+	m.Set("other", 2)
+	m.Compute("config/b", func(old int, ok bool) (int, bool) {
+		return 3, true
+	})
+	m.Delete("config/a")
+
+	var keys []string
+	for i := 0; i < 3; i++ {
+		keys = append(keys, (<-w.Events()).Key)
+	}
+	assert.Equal(t, []string{"config/a", "config/b", "config/a"}, keys)
+	assert.Equal(t, 0, len(w.Events()))
+}
+
+func TestWatchBackpressure(t *testing.T) {
+	testCase := []struct {
+		name   string
+		policy Backpressure
+		want   []int
+	}{
+		{
+			name:   "drop newest",
+			policy: DropNewest,
+			want:   []int{0, 1},
+		},
+		{
+			name:   "drop oldest",
+			policy: DropOldest,
+			want:   []int{3, 4},
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewShardMap[string, int]()
+			w := m.Watch("a", WithWatchBuffer(2), WithBackpressure(tc.policy))
+			for i := 0; i < 5; i++ {
+				m.Set("a", i)
+			}
+			w.Close()
+
+			var got []int
+			for ev := range w.Events() {
+				got = append(got, ev.New)
+			}
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, uint64(3), w.Dropped())
+		})
+	}
+}
+
+func TestWatchEvict(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(1), WithCapacity(1))
+	w := m.Watch(1)
+	defer w.Close()
+
+	m.Set(1, 1)
+	m.Set(2, 2)
+	assert.Equal(t, EventSet, (<-w.Events()).Type)
+	assert.Equal(t, EventDelete, (<-w.Events()).Type)
+}
+
+func TestWatchFunc(t *testing.T) {
+	m := NewShardMap[string, int]()
+	var (
+		mu  sync.Mutex
+		got []int
+	)
+	w := m.WatchFunc("a", func(ev Event[string, int]) {
+		mu.Lock()
+		got = append(got, ev.New)
+		mu.Unlock()
+	})
+	defer w.Close()
+
+	for i := 0; i < 10; i++ {
+		m.Set("a", i)
+	}
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got) == 10
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, got)
+}
+
+func TestWatchClose(t *testing.T) {
+	m := NewShardMap[string, int]()
+	w := m.Watch("a")
+	w.Close()
+	w.Close()
+
+	m.Set("a", 1)
+	_, ok := <-w.Events()
+	assert.False(t, ok)
+	assert.False(t, m.watchers.active())
+}
+
+func TestWatchConcurrent(t *testing.T) {
+	m := NewShardMap[int, int](WithShardCount(2))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				m.Set(j%10, j)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				w := m.Watch(j%10, WithWatchBuffer(1), WithBackpressure(DropOldest))
+				w.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.False(t, m.watchers.active())
+}
@@ -0,0 +1,246 @@
+package set
+
+import (
+	"encoding/binary"
+	"errors"
+	"iter"
+	"math/bits"
+)
+
+// BitSet 用位图表示的非负整数集合，适合元素较小且密集的场景，每个元素只占 1 bit。
+// 内存占用与最大元素成正比，元素稀疏时应使用 Set。
+// 不是并发安全的！！！
+
+const wordBits = 64
+
+var errBitSetLength = errors.New("set: BitSet binary data length is not a multiple of 8")
+
+type BitSet struct {
+	words []uint64
+}
+
+// NewBitSet 创建 BitSet，capacity 为预估的最大元素 + 1，用于预分配内存
+func NewBitSet(capacity uint) *BitSet {
+	return &BitSet{
+		words: make([]uint64, 0, (capacity+wordBits-1)/wordBits),
+	}
+}
+
+// BitSetFromSet 由 Set[uint] 创建 BitSet
+func BitSetFromSet(s *Set[uint]) *BitSet {
+	b := &BitSet{}
+	for v := range s.container {
+		b.Add(v)
+	}
+	return b
+}
+
+// Add 添加元素
+func (b *BitSet) Add(elems ...uint) {
+	for _, elem := range elems {
+		i := elem / wordBits
+		if i >= uint(len(b.words)) {
+			b.grow(i + 1)
+		}
+		b.words[i] |= 1 << (elem % wordBits)
+	}
+}
+
+// Remove 删除元素
+func (b *BitSet) Remove(elem uint) {
+	if i := elem / wordBits; i < uint(len(b.words)) {
+		b.words[i] &^= 1 << (elem % wordBits)
+	}
+}
+
+// Contains 是否包含元素
+func (b *BitSet) Contains(elem uint) bool {
+	i := elem / wordBits
+	return i < uint(len(b.words)) && b.words[i]&(1<<(elem%wordBits)) != 0
+}
+
+// Count 返回元素个数
+func (b *BitSet) Count() int {
+	n := 0
+	for _, w := range b.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// Clear 清空 BitSet，保留已分配的内存
+func (b *BitSet) Clear() {
+	clear(b.words)
+	b.words = b.words[:0]
+}
+
+// NextSet 返回大于等于 i 的最小元素
+func (b *BitSet) NextSet(i uint) (uint, bool) {
+	x := i / wordBits
+	if x >= uint(len(b.words)) {
+		return 0, false
+	}
+	// 去掉当前字中小于 i 的位
+	if w := b.words[x] >> (i % wordBits); w != 0 {
+		return i + uint(bits.TrailingZeros64(w)), true
+	}
+	for x++; x < uint(len(b.words)); x++ {
+		if w := b.words[x]; w != 0 {
+			return x*wordBits + uint(bits.TrailingZeros64(w)), true
+		}
+	}
+	return 0, false
+}
+
+// NextClear 返回大于等于 i 的最小的不在集合中的整数
+func (b *BitSet) NextClear(i uint) uint {
+	x := i / wordBits
+	if x >= uint(len(b.words)) {
+		return i
+	}
+	if w := ^b.words[x] >> (i % wordBits); w != 0 {
+		return i + uint(bits.TrailingZeros64(w))
+	}
+	for x++; x < uint(len(b.words)); x++ {
+		if w := ^b.words[x]; w != 0 {
+			return x*wordBits + uint(bits.TrailingZeros64(w))
+		}
+	}
+	return uint(len(b.words)) * wordBits
+}
+
+// Items 按从小到大的顺序返回所有元素
+func (b *BitSet) Items() []uint {
+	items := make([]uint, 0, b.Count())
+	for v := range b.All() {
+		items = append(items, v)
+	}
+	return items
+}
+
+// All 返回从小到大遍历所有元素的迭代器
+func (b *BitSet) All() iter.Seq[uint] {
+	return func(yield func(uint) bool) {
+		for x, w := range b.words {
+			for w != 0 {
+				t := bits.TrailingZeros64(w)
+				if !yield(uint(x)*wordBits + uint(t)) {
+					return
+				}
+				w &= w - 1
+			}
+		}
+	}
+}
+
+// ToSet 转换为 Set[uint]
+func (b *BitSet) ToSet() *Set[uint] {
+	s := &Set[uint]{container: make(map[uint]struct{}, b.Count())}
+	for v := range b.All() {
+		s.container[v] = struct{}{}
+	}
+	return s
+}
+
+// Clone 复制 BitSet
+func (b *BitSet) Clone() *BitSet {
+	return &BitSet{words: append([]uint64(nil), b.words...)}
+}
+
+// Equal 两个 BitSet 是否包含相同的元素
+func (b *BitSet) Equal(other *BitSet) bool {
+	short, long := b.words, other.words
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	for i, w := range short {
+		if w != long[i] {
+			return false
+		}
+	}
+	for _, w := range long[len(short):] {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Union 并集，按字计算
+func (b *BitSet) Union(other *BitSet) *BitSet {
+	short, long := b.words, other.words
+	if len(short) > len(long) {
+		short, long = long, short
+	}
+	result := &BitSet{words: append([]uint64(nil), long...)}
+	for i, w := range short {
+		result.words[i] |= w
+	}
+	return result
+}
+
+// Intersect 交集，按字计算
+func (b *BitSet) Intersect(other *BitSet) *BitSet {
+	n := min(len(b.words), len(other.words))
+	result := &BitSet{words: make([]uint64, n)}
+	for i := 0; i < n; i++ {
+		result.words[i] = b.words[i] & other.words[i]
+	}
+	result.trim()
+	return result
+}
+
+// Difference 差集，按字计算
+func (b *BitSet) Difference(other *BitSet) *BitSet {
+	result := b.Clone()
+	n := min(len(b.words), len(other.words))
+	for i := 0; i < n; i++ {
+		result.words[i] &^= other.words[i]
+	}
+	result.trim()
+	return result
+}
+
+// MarshalBinary 编码为小端序的 64 位字序列，末尾的空字不会输出
+func (b *BitSet) MarshalBinary() ([]byte, error) {
+	words := b.words
+	for len(words) > 0 && words[len(words)-1] == 0 {
+		words = words[:len(words)-1]
+	}
+	data := make([]byte, 0, len(words)*8)
+	for _, w := range words {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary 解码 MarshalBinary 的输出，会覆盖已有的元素
+func (b *BitSet) UnmarshalBinary(data []byte) error {
+	if len(data)%8 != 0 {
+		return errBitSetLength
+	}
+	words := make([]uint64, len(data)/8)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+	b.words = words
+	return nil
+}
+
+// grow 扩展到至少 n 个字
+func (b *BitSet) grow(n uint) {
+	if n <= uint(cap(b.words)) {
+		b.words = b.words[:n]
+		return
+	}
+	words := make([]uint64, n, max(n, 2*uint(cap(b.words))))
+	copy(words, b.words)
+	b.words = words
+}
+
+// trim 去掉末尾的空字
+func (b *BitSet) trim() {
+	for len(b.words) > 0 && b.words[len(b.words)-1] == 0 {
+		b.words = b.words[:len(b.words)-1]
+	}
+}
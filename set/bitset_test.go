@@ -0,0 +1,153 @@
+package set
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBitSetBasicOperations(t *testing.T) {
+	b := NewBitSet(128)
+	b.Add(1, 64, 65, 1000)
+
+	if b.Count() != 4 {
+		t.Errorf("expected count 4, got %d", b.Count())
+	}
+	if !b.Contains(64) || b.Contains(2) || b.Contains(5000) {
+		t.Errorf("contains failed")
+	}
+
+	b.Remove(64)
+	b.Remove(5000)
+	if b.Count() != 3 || b.Contains(64) {
+		t.Errorf("remove failed, items=%v", b.Items())
+	}
+	if !reflect.DeepEqual(b.Items(), []uint{1, 65, 1000}) {
+		t.Errorf("expected sorted items, got %v", b.Items())
+	}
+
+	b.Clear()
+	if b.Count() != 0 || b.Contains(1) {
+		t.Errorf("clear failed, count=%d", b.Count())
+	}
+	b.Add(3)
+	if !reflect.DeepEqual(b.Items(), []uint{3}) {
+		t.Errorf("add after clear failed, got %v", b.Items())
+	}
+}
+
+func TestBitSetNext(t *testing.T) {
+	b := NewBitSet(0)
+	b.Add(0, 1, 2, 63, 64, 200)
+
+	tests := []struct {
+		from      uint
+		wantSet   uint
+		wantOk    bool
+		wantClear uint
+	}{
+		{0, 0, true, 3},
+		{3, 63, true, 3},
+		{63, 63, true, 65},
+		{65, 200, true, 65},
+		{200, 200, true, 201},
+		{201, 0, false, 201},
+		{5000, 0, false, 5000},
+	}
+	for _, tt := range tests {
+		got, ok := b.NextSet(tt.from)
+		if got != tt.wantSet || ok != tt.wantOk {
+			t.Errorf("NextSet(%d): expected (%d, %v), got (%d, %v)", tt.from, tt.wantSet, tt.wantOk, got, ok)
+		}
+		if got := b.NextClear(tt.from); got != tt.wantClear {
+			t.Errorf("NextClear(%d): expected %d, got %d", tt.from, tt.wantClear, got)
+		}
+	}
+
+	full := NewBitSet(0)
+	for i := uint(0); i < 128; i++ {
+		full.Add(i)
+	}
+	if got := full.NextClear(10); got != 128 {
+		t.Errorf("NextClear on full words: expected 128, got %d", got)
+	}
+}
+
+func TestBitSetOperations(t *testing.T) {
+	a := NewBitSet(0)
+	a.Add(1, 2, 3, 100)
+	b := NewBitSet(0)
+	b.Add(3, 4, 300)
+
+	tests := []struct {
+		name string
+		got  *BitSet
+		want []uint
+	}{
+		{"union", a.Union(b), []uint{1, 2, 3, 4, 100, 300}},
+		{"intersect", a.Intersect(b), []uint{3}},
+		{"difference", a.Difference(b), []uint{1, 2, 100}},
+		{"difference reverse", b.Difference(a), []uint{4, 300}},
+	}
+	for _, tt := range tests {
+		if got := tt.got.Items(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	c := a.Clone()
+	c.Add(1000)
+	c.Remove(1000)
+	if !a.Equal(c) || a.Equal(b) {
+		t.Errorf("equal should ignore trailing empty words")
+	}
+}
+
+func TestBitSetBinary(t *testing.T) {
+	b := NewBitSet(0)
+	b.Add(0, 63, 64, 1023)
+	b.Add(5000)
+	b.Remove(5000)
+
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 末尾的空字不会被编码
+	if len(data) != 16*8 {
+		t.Errorf("expected 128 bytes, got %d", len(data))
+	}
+
+	var got BitSet
+	if err := got.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(b) {
+		t.Errorf("expected %v, got %v", b.Items(), got.Items())
+	}
+	if err := got.UnmarshalBinary([]byte{1, 2, 3}); err == nil {
+		t.Errorf("expected error for invalid length")
+	}
+}
+
+func TestBitSetConvertSet(t *testing.T) {
+	s := NewSetFrom[uint](1, 5, 130)
+	b := BitSetFromSet(s)
+	if !reflect.DeepEqual(b.Items(), []uint{1, 5, 130}) {
+		t.Errorf("BitSetFromSet failed, got %v", b.Items())
+	}
+	if !compareSets(b.ToSet(), s) {
+		t.Errorf("ToSet failed, got %v", b.ToSet().Items())
+	}
+}
+
+func BenchmarkBitSetContains(b *testing.B) {
+	bs := NewBitSet(1 << 16)
+	for i := uint(0); i < 1<<16; i += 3 {
+		bs.Add(i)
+	}
+	var i uint
+	for b.Loop() {
+		bs.Contains(i & (1<<16 - 1))
+		i++
+	}
+}
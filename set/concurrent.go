@@ -0,0 +1,102 @@
+package set
+
+import (
+	"iter"
+
+	"github.com/Ri0nGo/gokit/maps"
+)
+
+// ConcurrentSet 并发安全的 set，基于 maps.ShardMap 实现，不同元素的读写分散在不同分片的锁上
+
+type ConcurrentSet[T comparable] struct {
+	m *maps.ShardMap[T, struct{}]
+}
+
+// NewConcurrentSet 创建 ConcurrentSet，opts 与 maps.NewShardMap 相同，例如 maps.WithShardCount
+func NewConcurrentSet[T comparable](opts ...maps.Option) *ConcurrentSet[T] {
+	return &ConcurrentSet[T]{
+		m: maps.NewShardMap[T, struct{}](opts...),
+	}
+}
+
+// Add 添加元素
+func (s *ConcurrentSet[T]) Add(elems ...T) {
+	if len(elems) == 1 {
+		s.m.Set(elems[0], struct{}{})
+		return
+	}
+	items := make(map[T]struct{}, len(elems))
+	for _, elem := range elems {
+		items[elem] = struct{}{}
+	}
+	s.m.SetMany(items)
+}
+
+// AddIfAbsent 元素不存在时添加，返回是否为新添加的元素
+func (s *ConcurrentSet[T]) AddIfAbsent(elem T) bool {
+	_, loaded := s.m.GetOrSet(elem, struct{}{})
+	return !loaded
+}
+
+// Delete 删除元素
+func (s *ConcurrentSet[T]) Delete(elem T) {
+	s.m.Delete(elem)
+}
+
+// Clear 清空set
+func (s *ConcurrentSet[T]) Clear() {
+	s.m.Clear()
+}
+
+// Len 统计set长度
+func (s *ConcurrentSet[T]) Len() int {
+	return s.m.Len()
+}
+
+// Contains 是否包含元素
+func (s *ConcurrentSet[T]) Contains(elem T) bool {
+	_, ok := s.m.Get(elem)
+	return ok
+}
+
+// Items 返回set中的所有元素，各分片依次复制，不保证跨分片一致
+func (s *ConcurrentSet[T]) Items() []T {
+	return s.m.Keys()
+}
+
+// All 返回遍历 set 中所有元素的迭代器，循环体中可以安全地修改 set
+func (s *ConcurrentSet[T]) All() iter.Seq[T] {
+	return s.m.KeysSeq()
+}
+
+// Snapshot 返回某一时刻所有元素的副本
+func (s *ConcurrentSet[T]) Snapshot() *Set[T] {
+	sn := s.m.Snapshot()
+	result := &Set[T]{container: make(map[T]struct{}, sn.Len())}
+	for elem := range sn.KeysSeq() {
+		result.container[elem] = struct{}{}
+	}
+	return result
+}
+
+// Union 并集，基于两个 set 各自的快照计算
+func (s *ConcurrentSet[T]) Union(other *ConcurrentSet[T]) *ConcurrentSet[T] {
+	return s.fromSet(s.Snapshot().Union(other.Snapshot()))
+}
+
+// Intersect 交集，基于两个 set 各自的快照计算
+func (s *ConcurrentSet[T]) Intersect(other *ConcurrentSet[T]) *ConcurrentSet[T] {
+	return s.fromSet(s.Snapshot().Intersect(other.Snapshot()))
+}
+
+// Difference 差集，基于两个 set 各自的快照计算
+func (s *ConcurrentSet[T]) Difference(other *ConcurrentSet[T]) *ConcurrentSet[T] {
+	return s.fromSet(s.Snapshot().Difference(other.Snapshot()))
+}
+
+// fromSet 创建与 s 分片数相同的 ConcurrentSet 并写入 set 中的元素
+func (s *ConcurrentSet[T]) fromSet(set *Set[T]) *ConcurrentSet[T] {
+	result := NewConcurrentSet[T](maps.WithShardCount(uint64(s.m.ShardCount())))
+	result.m.SetMany(set.container)
+	return result
+}
@@ -0,0 +1,102 @@
+package set
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConcurrentSetBasicOperations(t *testing.T) {
+	s := NewConcurrentSet[int]()
+
+	s.Add(1, 2, 3)
+	if s.Len() != 3 {
+		t.Errorf("expected length 3, got %d", s.Len())
+	}
+	if !s.Contains(2) || s.Contains(4) {
+		t.Errorf("contains failed")
+	}
+
+	if s.AddIfAbsent(2) {
+		t.Errorf("expected AddIfAbsent(2) to report existing element")
+	}
+	if !s.AddIfAbsent(4) {
+		t.Errorf("expected AddIfAbsent(4) to report new element")
+	}
+
+	s.Delete(2)
+	if s.Len() != 3 || s.Contains(2) {
+		t.Errorf("delete failed, len=%d, contains 2=%v", s.Len(), s.Contains(2))
+	}
+
+	items := s.Items()
+	sort.Ints(items)
+	if !reflect.DeepEqual(items, []int{1, 3, 4}) {
+		t.Errorf("expected items [1 3 4], got %v", items)
+	}
+
+	s.Clear()
+	if s.Len() != 0 {
+		t.Errorf("clear failed, len=%d", s.Len())
+	}
+}
+
+func TestConcurrentSetOperations(t *testing.T) {
+	a := NewConcurrentSet[int]()
+	a.Add(1, 2, 3)
+	b := NewConcurrentSet[int]()
+	b.Add(2, 3, 4)
+
+	tests := []struct {
+		name string
+		got  *ConcurrentSet[int]
+		want []int
+	}{
+		{"union", a.Union(b), []int{1, 2, 3, 4}},
+		{"intersect", a.Intersect(b), []int{2, 3}},
+		{"difference", a.Difference(b), []int{1}},
+	}
+	for _, tt := range tests {
+		items := tt.got.Items()
+		sort.Ints(items)
+		if !reflect.DeepEqual(items, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, items)
+		}
+	}
+}
+
+func TestConcurrentSetAddIfAbsentConcurrent(t *testing.T) {
+	s := NewConcurrentSet[int]()
+	var added atomic.Int64
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if s.AddIfAbsent(i) {
+					added.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 每个元素只会被一个协程添加成功
+	if added.Load() != 1000 || s.Len() != 1000 {
+		t.Errorf("expected 1000 elements added once, got added=%d len=%d", added.Load(), s.Len())
+	}
+}
+
+func TestConcurrentSetSnapshot(t *testing.T) {
+	s := NewConcurrentSet[string]()
+	s.Add("a", "b")
+	snap := s.Snapshot()
+	s.Add("c")
+
+	if snap.Len() != 2 || snap.Contains("c") {
+		t.Errorf("snapshot changed after Add, items=%v", snap.Items())
+	}
+}
@@ -0,0 +1,133 @@
+package set
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+
+	"github.com/Ri0nGo/gokit/internal/codec"
+)
+
+// MarshalJSON 编码为 JSON 数组，元素按值（或其 JSON 编码）排序，输出是确定的
+func (s *Set[T]) MarshalJSON() ([]byte, error) {
+	return codec.MarshalSlice(s.Items())
+}
+
+// UnmarshalJSON 解码 JSON 数组，已有的元素会保留，可以直接解码到零值的 Set 中
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	s.addAll(items)
+	return nil
+}
+
+// GobEncode 使用 gob 编码
+func (s *Set[T]) GobEncode() ([]byte, error) {
+	return gobEncode(s.Items())
+}
+
+// GobDecode 解码 GobEncode 的输出
+func (s *Set[T]) GobDecode(data []byte) error {
+	items, err := gobDecode[T](data)
+	if err != nil {
+		return err
+	}
+	s.addAll(items)
+	return nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler，与 GobEncode 相同
+func (s *Set[T]) MarshalBinary() ([]byte, error) {
+	return s.GobEncode()
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler，与 GobDecode 相同
+func (s *Set[T]) UnmarshalBinary(data []byte) error {
+	return s.GobDecode(data)
+}
+
+// addAll 添加解码得到的元素，零值的 Set 会先初始化
+func (s *Set[T]) addAll(items []T) {
+	if s.container == nil {
+		s.container = make(map[T]struct{}, len(items))
+	}
+	s.Add(items...)
+}
+
+// SortedSet 的序列化，按从小到大的顺序编码为元素数组。
+// 解码需要比较函数，只能解码到通过 NewSortedSet/NewSortedSetFunc 创建的 SortedSet 中，已有的元素会保留
+
+var errSortedSetNoCmp = errors.New("set: SortedSet must be created by NewSortedSet or NewSortedSetFunc before decoding")
+
+// MarshalJSON 按从小到大的顺序编码为 JSON 数组
+func (s *SortedSet[T]) MarshalJSON() ([]byte, error) {
+	return codec.MarshalOrdered(s.sortedItems())
+}
+
+// UnmarshalJSON 解码 JSON 数组
+func (s *SortedSet[T]) UnmarshalJSON(data []byte) error {
+	if s.cmp == nil {
+		return errSortedSetNoCmp
+	}
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	s.Add(items...)
+	return nil
+}
+
+// GobEncode 使用 gob 编码，保留元素顺序
+func (s *SortedSet[T]) GobEncode() ([]byte, error) {
+	return gobEncode(s.sortedItems())
+}
+
+// GobDecode 解码 GobEncode 的输出
+func (s *SortedSet[T]) GobDecode(data []byte) error {
+	if s.cmp == nil {
+		return errSortedSetNoCmp
+	}
+	items, err := gobDecode[T](data)
+	if err != nil {
+		return err
+	}
+	s.Add(items...)
+	return nil
+}
+
+// MarshalBinary 实现 encoding.BinaryMarshaler，与 GobEncode 相同
+func (s *SortedSet[T]) MarshalBinary() ([]byte, error) {
+	return s.GobEncode()
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler，与 GobDecode 相同
+func (s *SortedSet[T]) UnmarshalBinary(data []byte) error {
+	return s.GobDecode(data)
+}
+
+// sortedItems 与 Items 相同，零值的 SortedSet 返回 nil
+func (s *SortedSet[T]) sortedItems() []T {
+	if s.head == nil {
+		return nil
+	}
+	return s.Items()
+}
+
+func gobEncode[T any](items []T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(items); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gobDecode[T any](data []byte) ([]T, error) {
+	var items []T
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,123 @@
+package set
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/gob"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSetJSON(t *testing.T) {
+	s := NewSet[int]()
+	s.Add(10, 3, -1, 7)
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if string(data) != `[-1,3,7,10]` {
+		t.Errorf("expected sorted json array, got %s", data)
+	}
+
+	var restored Set[int]
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if !compareSets(&restored, s) {
+		t.Errorf("json round trip failed, got %v", restored.Items())
+	}
+
+	empty, _ := json.Marshal(NewSet[string]())
+	if string(empty) != `[]` {
+		t.Errorf("expected empty json array, got %s", empty)
+	}
+}
+
+func TestSetGob(t *testing.T) {
+	s := NewSet[string]()
+	s.Add("a", "b", "c")
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s); err != nil {
+		t.Fatalf("gob encode failed: %v", err)
+	}
+	restored := NewSet[string]()
+	if err := gob.NewDecoder(&buf).Decode(restored); err != nil {
+		t.Fatalf("gob decode failed: %v", err)
+	}
+	if !compareSets(restored, s) {
+		t.Errorf("gob round trip failed, got %v", restored.Items())
+	}
+
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal binary failed: %v", err)
+	}
+	var fromBinary Set[string]
+	if err := fromBinary.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal binary failed: %v", err)
+	}
+	if !compareSets(&fromBinary, s) {
+		t.Errorf("binary round trip failed, got %v", fromBinary.Items())
+	}
+}
+
+func TestSortedSetEncoding(t *testing.T) {
+	s := NewSortedSetFunc(func(a, b string) int {
+		// 按长度排序，保证编码顺序不是字典序
+		return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+	})
+	s.Add("ccc", "a", "bb", "dddd")
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if string(data) != `["a","bb","ccc","dddd"]` {
+		t.Errorf("expected items in set order, got %s", data)
+	}
+	restored := NewSortedSetFunc(s.cmp)
+	restored.Add("e")
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if !slices.Equal(restored.Items(), []string{"a", "e", "bb", "ccc", "dddd"}) {
+		t.Errorf("json round trip failed, got %v", restored.Items())
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(s); err != nil {
+		t.Fatalf("gob encode failed: %v", err)
+	}
+	decoded := NewSortedSetFunc(s.cmp)
+	if err := gob.NewDecoder(&buf).Decode(decoded); err != nil {
+		t.Fatalf("gob decode failed: %v", err)
+	}
+	if !decoded.Equal(s) {
+		t.Errorf("gob round trip failed, got %v", decoded.Items())
+	}
+
+	bin, err := NewSortedSet(3, 1, 2).MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal binary failed: %v", err)
+	}
+	ints := NewSortedSet[int]()
+	if err := ints.UnmarshalBinary(bin); err != nil || !slices.Equal(ints.Items(), []int{1, 2, 3}) {
+		t.Errorf("binary round trip failed, got %v, err %v", ints.Items(), err)
+	}
+
+	// 零值没有比较函数，可以编码但不能解码
+	var zero SortedSet[int]
+	if data, err := json.Marshal(&zero); err != nil || string(data) != `[]` {
+		t.Errorf("expected empty json array, got %s, err %v", data, err)
+	}
+	if err := json.Unmarshal([]byte(`[1]`), &zero); err == nil {
+		t.Errorf("expected error decoding into zero value")
+	}
+	if err := zero.UnmarshalBinary(bin); err == nil {
+		t.Errorf("expected error decoding into zero value")
+	}
+}
@@ -0,0 +1,213 @@
+package set
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// MultiSet 多重集（Bag），记录每个元素出现的次数，次数为 0 的元素会被删除
+// 不是并发安全的！！！
+type MultiSet[T comparable] struct {
+	container map[T]int
+	// size 所有元素的次数之和
+	size int
+}
+
+// ElemCount 元素及其出现次数
+type ElemCount[T comparable] struct {
+	Elem  T
+	Count int
+}
+
+func NewMultiSet[T comparable]() *MultiSet[T] {
+	return &MultiSet[T]{
+		container: make(map[T]int),
+	}
+}
+
+// MultiSetFromSlice 由切片创建多重集，统计每个元素出现的次数。
+// 与 slice.SetSlice 去重的结果相比保留了次数
+func MultiSetFromSlice[T comparable](slice []T) *MultiSet[T] {
+	m := &MultiSet[T]{
+		container: make(map[T]int),
+	}
+	for _, elem := range slice {
+		m.container[elem]++
+	}
+	m.size = len(slice)
+	return m
+}
+
+// Add 将 elem 的次数增加 n，n <= 0 时不做任何事
+func (m *MultiSet[T]) Add(elem T, n int) {
+	if n <= 0 {
+		return
+	}
+	m.container[elem] += n
+	m.size += n
+}
+
+// Remove 将 elem 的次数减少 n（最多减到 0），返回实际减少的次数
+func (m *MultiSet[T]) Remove(elem T, n int) int {
+	cnt, ok := m.container[elem]
+	if !ok || n <= 0 {
+		return 0
+	}
+	if n >= cnt {
+		delete(m.container, elem)
+		m.size -= cnt
+		return cnt
+	}
+	m.container[elem] = cnt - n
+	m.size -= n
+	return n
+}
+
+// Count 返回 elem 出现的次数
+func (m *MultiSet[T]) Count(elem T) int {
+	return m.container[elem]
+}
+
+// Contains 是否包含元素
+func (m *MultiSet[T]) Contains(elem T) bool {
+	_, ok := m.container[elem]
+	return ok
+}
+
+// Len 返回所有元素的次数之和
+func (m *MultiSet[T]) Len() int {
+	return m.size
+}
+
+// Distinct 返回不同元素的个数
+func (m *MultiSet[T]) Distinct() int {
+	return len(m.container)
+}
+
+// Clear 清空
+func (m *MultiSet[T]) Clear() {
+	m.container = make(map[T]int)
+	m.size = 0
+}
+
+// Items 返回所有不同的元素
+func (m *MultiSet[T]) Items() []T {
+	keys := make([]T, 0, len(m.container))
+	for key := range m.container {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// All 返回遍历所有元素及其次数的迭代器
+func (m *MultiSet[T]) All() iter.Seq2[T, int] {
+	return func(yield func(T, int) bool) {
+		for key, cnt := range m.container {
+			if !yield(key, cnt) {
+				return
+			}
+		}
+	}
+}
+
+// MostCommon 按次数从大到小返回出现最多的 k 个元素，k < 0 时返回全部，次数相同的元素顺序不确定
+func (m *MultiSet[T]) MostCommon(k int) []ElemCount[T] {
+	res := make([]ElemCount[T], 0, len(m.container))
+	for key, cnt := range m.container {
+		res = append(res, ElemCount[T]{Elem: key, Count: cnt})
+	}
+	slices.SortFunc(res, func(a, b ElemCount[T]) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+	if k >= 0 && k < len(res) {
+		res = res[:k]
+	}
+	return res
+}
+
+// ToSet 返回所有不同元素组成的 set
+func (m *MultiSet[T]) ToSet() *Set[T] {
+	s := &Set[T]{
+		container: make(map[T]struct{}, len(m.container)),
+	}
+	for key := range m.container {
+		s.container[key] = struct{}{}
+	}
+	return s
+}
+
+// Clone 复制多重集
+func (m *MultiSet[T]) Clone() *MultiSet[T] {
+	res := &MultiSet[T]{
+		container: make(map[T]int, len(m.container)),
+		size:      m.size,
+	}
+	for key, cnt := range m.container {
+		res.container[key] = cnt
+	}
+	return res
+}
+
+// Equal 两个多重集的元素及次数是否完全相同
+func (m *MultiSet[T]) Equal(other *MultiSet[T]) bool {
+	if m.size != other.size || len(m.container) != len(other.container) {
+		return false
+	}
+	for key, cnt := range m.container {
+		if other.container[key] != cnt {
+			return false
+		}
+	}
+	return true
+}
+
+// Union 并集，每个元素的次数取两者中的较大值
+func (m *MultiSet[T]) Union(other *MultiSet[T]) *MultiSet[T] {
+	res := m.Clone()
+	for key, cnt := range other.container {
+		if cur := res.container[key]; cnt > cur {
+			res.container[key] = cnt
+			res.size += cnt - cur
+		}
+	}
+	return res
+}
+
+// Sum 和，每个元素的次数为两者之和
+func (m *MultiSet[T]) Sum(other *MultiSet[T]) *MultiSet[T] {
+	res := m.Clone()
+	for key, cnt := range other.container {
+		res.container[key] += cnt
+	}
+	res.size += other.size
+	return res
+}
+
+// Intersect 交集，每个元素的次数取两者中的较小值
+func (m *MultiSet[T]) Intersect(other *MultiSet[T]) *MultiSet[T] {
+	small, large := m, other
+	if len(small.container) > len(large.container) {
+		small, large = large, small
+	}
+	res := NewMultiSet[T]()
+	for key, cnt := range small.container {
+		if n := min(cnt, large.container[key]); n > 0 {
+			res.container[key] = n
+			res.size += n
+		}
+	}
+	return res
+}
+
+// Difference 差集，每个元素的次数为 m 中的次数减去 other 中的次数，小于等于 0 的元素不保留
+func (m *MultiSet[T]) Difference(other *MultiSet[T]) *MultiSet[T] {
+	res := NewMultiSet[T]()
+	for key, cnt := range m.container {
+		if n := cnt - other.container[key]; n > 0 {
+			res.container[key] = n
+			res.size += n
+		}
+	}
+	return res
+}
@@ -0,0 +1,133 @@
+package set
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMultiSetBasicOperations(t *testing.T) {
+	m := NewMultiSet[string]()
+	m.Add("a", 3)
+	m.Add("b", 1)
+	m.Add("c", 0)
+	m.Add("c", -1)
+
+	if m.Len() != 4 || m.Distinct() != 2 {
+		t.Errorf("expected len 4 distinct 2, got len %d distinct %d", m.Len(), m.Distinct())
+	}
+	if m.Count("a") != 3 || m.Count("c") != 0 || m.Contains("c") {
+		t.Errorf("unexpected counts a=%d c=%d", m.Count("a"), m.Count("c"))
+	}
+
+	if n := m.Remove("a", 2); n != 2 || m.Count("a") != 1 {
+		t.Errorf("remove 2 of a: removed %d, left %d", n, m.Count("a"))
+	}
+	// 超过现有次数时全部删除
+	if n := m.Remove("a", 5); n != 1 || m.Contains("a") {
+		t.Errorf("remove 5 of a: removed %d, contains %v", n, m.Contains("a"))
+	}
+	if n := m.Remove("x", 1); n != 0 {
+		t.Errorf("remove missing: removed %d", n)
+	}
+	if m.Len() != 1 || m.Distinct() != 1 {
+		t.Errorf("expected len 1 distinct 1, got len %d distinct %d", m.Len(), m.Distinct())
+	}
+
+	m.Clear()
+	if m.Len() != 0 || m.Distinct() != 0 {
+		t.Errorf("clear failed, len=%d", m.Len())
+	}
+}
+
+func TestMultiSetFromSlice(t *testing.T) {
+	m := MultiSetFromSlice([]int{1, 2, 4, 2, 5, 2, 1})
+	expected := map[int]int{1: 2, 2: 3, 4: 1, 5: 1}
+
+	got := make(map[int]int)
+	for elem, cnt := range m.All() {
+		got[elem] = cnt
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if m.Len() != 7 {
+		t.Errorf("expected len 7, got %d", m.Len())
+	}
+
+	items := m.Items()
+	sort.Ints(items)
+	if !reflect.DeepEqual(items, []int{1, 2, 4, 5}) {
+		t.Errorf("unexpected items %v", items)
+	}
+	if !m.ToSet().Equal(NewSetFrom(1, 2, 4, 5)) {
+		t.Errorf("unexpected set %v", m.ToSet().Items())
+	}
+}
+
+func TestMultiSetMostCommon(t *testing.T) {
+	m := MultiSetFromSlice([]string{"a", "b", "b", "c", "c", "c", "d", "d", "d", "d"})
+
+	testCase := []struct {
+		name     string
+		k        int
+		expected []ElemCount[string]
+	}{
+		{name: "top 2", k: 2, expected: []ElemCount[string]{{"d", 4}, {"c", 3}}},
+		{name: "zero", k: 0, expected: []ElemCount[string]{}},
+		{name: "all", k: -1, expected: []ElemCount[string]{{"d", 4}, {"c", 3}, {"b", 2}, {"a", 1}}},
+		{name: "more than distinct", k: 10, expected: []ElemCount[string]{{"d", 4}, {"c", 3}, {"b", 2}, {"a", 1}}},
+	}
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := m.MostCommon(tc.k); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestMultiSetAlgebra(t *testing.T) {
+	a := MultiSetFromSlice([]int{1, 1, 1, 2, 2, 3})
+	b := MultiSetFromSlice([]int{1, 2, 2, 2, 4})
+
+	testCase := []struct {
+		name     string
+		got      *MultiSet[int]
+		expected *MultiSet[int]
+	}{
+		{name: "union", got: a.Union(b), expected: MultiSetFromSlice([]int{1, 1, 1, 2, 2, 2, 3, 4})},
+		{name: "sum", got: a.Sum(b), expected: MultiSetFromSlice([]int{1, 1, 1, 1, 2, 2, 2, 2, 2, 3, 4})},
+		{name: "intersect", got: a.Intersect(b), expected: MultiSetFromSlice([]int{1, 2, 2})},
+		{name: "difference", got: a.Difference(b), expected: MultiSetFromSlice([]int{1, 1, 3})},
+		{name: "difference reverse", got: b.Difference(a), expected: MultiSetFromSlice([]int{2, 4})},
+	}
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			if !tc.got.Equal(tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected.MostCommon(-1), tc.got.MostCommon(-1))
+			}
+			if tc.got.Len() != tc.expected.Len() {
+				t.Errorf("expected len %d, got %d", tc.expected.Len(), tc.got.Len())
+			}
+		})
+	}
+
+	// 运算不修改原多重集
+	if !a.Equal(MultiSetFromSlice([]int{1, 1, 1, 2, 2, 3})) {
+		t.Errorf("union modified receiver: %v", a.MostCommon(-1))
+	}
+}
+
+func TestMultiSetClone(t *testing.T) {
+	m := MultiSetFromSlice([]int{1, 1, 2})
+	c := m.Clone()
+	c.Add(3, 2)
+	c.Remove(1, 1)
+	if m.Count(1) != 2 || m.Contains(3) || m.Len() != 3 {
+		t.Errorf("clone shares state with original")
+	}
+	if c.Len() != 4 || c.Equal(m) {
+		t.Errorf("unexpected clone len %d", c.Len())
+	}
+}
@@ -0,0 +1,110 @@
+package set
+
+import (
+	"iter"
+
+	"github.com/Ri0nGo/gokit/maps"
+)
+
+// PersistentSet 不可变的持久化 set，基于 maps.PersistentMap 实现。
+// Add/Delete 返回共享未修改部分的新版本，任意版本都可以被多个 goroutine 同时读取
+
+type PersistentSet[T comparable] struct {
+	m *maps.PersistentMap[T, struct{}]
+}
+
+// NewPersistentSet 创建空的 PersistentSet，可通过 maps.WithPersistentHasher 指定哈希函数
+func NewPersistentSet[T comparable](opts ...maps.PersistentOption[T]) *PersistentSet[T] {
+	return &PersistentSet[T]{
+		m: maps.NewPersistentMap[T, struct{}](opts...),
+	}
+}
+
+// Add 返回添加了 elems 的新版本
+func (s *PersistentSet[T]) Add(elems ...T) *PersistentSet[T] {
+	if len(elems) == 1 {
+		return &PersistentSet[T]{m: s.m.Set(elems[0], struct{}{})}
+	}
+	t := s.Transient()
+	t.Add(elems...)
+	return t.Persistent()
+}
+
+// Delete 返回删除了 elem 的新版本，elem 不存在时返回 s 本身
+func (s *PersistentSet[T]) Delete(elem T) *PersistentSet[T] {
+	m := s.m.Delete(elem)
+	if m == s.m {
+		return s
+	}
+	return &PersistentSet[T]{m: m}
+}
+
+// Len 统计set长度
+func (s *PersistentSet[T]) Len() int {
+	return s.m.Len()
+}
+
+// Contains 是否包含元素
+func (s *PersistentSet[T]) Contains(elem T) bool {
+	return s.m.Contains(elem)
+}
+
+// Items 返回set中的所有元素
+func (s *PersistentSet[T]) Items() []T {
+	return s.m.Keys()
+}
+
+// All 返回遍历 set 中所有元素的迭代器
+func (s *PersistentSet[T]) All() iter.Seq[T] {
+	return s.m.KeysSeq()
+}
+
+// ToSet 复制为普通的 Set
+func (s *PersistentSet[T]) ToSet() *Set[T] {
+	res := &Set[T]{
+		container: make(map[T]struct{}, s.m.Len()),
+	}
+	for elem := range s.m.KeysSeq() {
+		res.container[elem] = struct{}{}
+	}
+	return res
+}
+
+// Transient 返回基于当前版本的 TransientSet，用于批量写入，不影响 s
+func (s *PersistentSet[T]) Transient() *TransientSet[T] {
+	return &TransientSet[T]{t: s.m.Transient()}
+}
+
+// TransientSet PersistentSet 的可变构建器
+// 不是并发安全的！！！
+type TransientSet[T comparable] struct {
+	t *maps.TransientMap[T, struct{}]
+}
+
+// Add 添加元素
+func (s *TransientSet[T]) Add(elems ...T) {
+	for _, elem := range elems {
+		s.t.Set(elem, struct{}{})
+	}
+}
+
+// Delete 删除元素，返回元素是否存在
+func (s *TransientSet[T]) Delete(elem T) bool {
+	return s.t.Delete(elem)
+}
+
+// Len 统计set长度
+func (s *TransientSet[T]) Len() int {
+	return s.t.Len()
+}
+
+// Contains 是否包含元素
+func (s *TransientSet[T]) Contains(elem T) bool {
+	_, ok := s.t.Get(elem)
+	return ok
+}
+
+// Persistent 返回当前内容的 PersistentSet，之后 TransientSet 仍可继续使用
+func (s *TransientSet[T]) Persistent() *PersistentSet[T] {
+	return &PersistentSet[T]{m: s.t.Persistent()}
+}
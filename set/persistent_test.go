@@ -0,0 +1,74 @@
+package set
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPersistentSet(t *testing.T) {
+	s0 := NewPersistentSet[int]()
+	s1 := s0.Add(1)
+	s2 := s1.Add(2, 3, 3)
+	s3 := s2.Delete(1)
+
+	testCase := []struct {
+		name     string
+		s        *PersistentSet[int]
+		expected []int
+	}{
+		{name: "empty", s: s0, expected: []int{}},
+		{name: "add one", s: s1, expected: []int{1}},
+		{name: "add many", s: s2, expected: []int{1, 2, 3}},
+		{name: "delete", s: s3, expected: []int{2, 3}},
+	}
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			items := tc.s.Items()
+			sort.Ints(items)
+			if !reflect.DeepEqual(items, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, items)
+			}
+			if tc.s.Len() != len(tc.expected) {
+				t.Errorf("expected len %d, got %d", len(tc.expected), tc.s.Len())
+			}
+			if !tc.s.ToSet().Equal(FromSlice(tc.expected)) {
+				t.Errorf("unexpected set %v", tc.s.ToSet().Items())
+			}
+		})
+	}
+
+	if s3.Delete(10) != s3 {
+		t.Errorf("deleting a missing element should return the same set")
+	}
+	if !s2.Contains(1) || s3.Contains(1) {
+		t.Errorf("delete modified the previous version")
+	}
+}
+
+func TestTransientSet(t *testing.T) {
+	base := NewPersistentSet[string]().Add("base")
+
+	tr := base.Transient()
+	tr.Add("a", "b", "c")
+	if !tr.Delete("base") || tr.Delete("base") {
+		t.Errorf("unexpected delete result")
+	}
+	s := tr.Persistent()
+	tr.Add("d")
+
+	var items []string
+	for v := range s.All() {
+		items = append(items, v)
+	}
+	sort.Strings(items)
+	if !reflect.DeepEqual(items, []string{"a", "b", "c"}) {
+		t.Errorf("expected [a b c], got %v", items)
+	}
+	if !tr.Contains("d") || s.Contains("d") || tr.Len() != 4 {
+		t.Errorf("transient changes leaked into the persistent version")
+	}
+	if base.Len() != 1 || !base.Contains("base") {
+		t.Errorf("base version was modified")
+	}
+}
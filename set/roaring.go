@@ -0,0 +1,460 @@
+package set
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"iter"
+	"slices"
+)
+
+// Roaring 压缩位图，适合元素数量大、同时存在稀疏和密集区域的 uint32 集合。
+// 按高 16 位将元素分到不同的容器中，每个容器根据元素分布选择数组、位图或区间表示，
+// 序列化格式与 RoaringFormatSpec（https://github.com/RoaringBitmap/RoaringFormatSpec）一致，
+// 可以与其他语言的 roaring 实现互通。
+// 不是并发安全的！！！
+
+const (
+	serialCookieNoRun = 12346
+	serialCookie      = 12347
+	// noOffsetThreshold 含有区间容器且容器数小于该值时，序列化格式中没有偏移量
+	noOffsetThreshold = 4
+)
+
+var errRoaringFormat = errors.New("set: invalid roaring bitmap data")
+
+type Roaring struct {
+	keys       []uint16
+	containers []container
+}
+
+// NewRoaring 创建包含 vals 的 Roaring
+func NewRoaring(vals ...uint32) *Roaring {
+	r := &Roaring{}
+	r.Add(vals...)
+	return r
+}
+
+// RoaringFromSet 由 Set[uint32] 创建 Roaring
+func RoaringFromSet(s *Set[uint32]) *Roaring {
+	vals := make([]uint32, 0, s.Len())
+	for v := range s.container {
+		vals = append(vals, v)
+	}
+	// 排序后写入，数组容器的插入都追加在末尾
+	slices.Sort(vals)
+	return NewRoaring(vals...)
+}
+
+// Add 添加元素
+func (r *Roaring) Add(vals ...uint32) {
+	for _, v := range vals {
+		hi, lo := split(v)
+		i, found := slices.BinarySearch(r.keys, hi)
+		if !found {
+			r.keys = slices.Insert(r.keys, i, hi)
+			r.containers = slices.Insert(r.containers, i, container(&arrayContainer{}))
+		}
+		r.containers[i] = r.containers[i].add(lo)
+	}
+}
+
+// AddRange 添加闭区间 [lo, hi] 中的所有元素，lo > hi 时不做任何事。
+// 只修改高 16 位落在区间内的容器，被完整覆盖的容器直接替换为一个区间容器
+func (r *Roaring) AddRange(lo, hi uint32) {
+	if lo > hi {
+		return
+	}
+	loKey, hiKey := uint32(lo>>16), uint32(hi>>16)
+	// [i, j) 为区间内已有的容器
+	i, _ := slices.BinarySearch(r.keys, uint16(loKey))
+	j := i
+	for j < len(r.keys) && uint32(r.keys[j]) <= hiKey {
+		j++
+	}
+
+	keys := make([]uint16, 0, hiKey-loKey+1)
+	containers := make([]container, 0, hiKey-loKey+1)
+	k := i
+	for key := loKey; key <= hiKey; key++ {
+		iv := interval16{start: 0, last: 0xffff}
+		if key == loKey {
+			iv.start = uint16(lo)
+		}
+		if key == hiKey {
+			iv.last = uint16(hi)
+		}
+		var c container = &runContainer{runs: []interval16{iv}}
+		if k < j && uint32(r.keys[k]) == key {
+			if iv.start != 0 || iv.last != 0xffff {
+				c = containerOr(r.containers[k], c)
+			}
+			k++
+		}
+		keys = append(keys, uint16(key))
+		containers = append(containers, c)
+	}
+	r.keys = slices.Replace(r.keys, i, j, keys...)
+	r.containers = slices.Replace(r.containers, i, j, containers...)
+}
+
+// Remove 删除元素
+func (r *Roaring) Remove(v uint32) {
+	hi, lo := split(v)
+	i, found := slices.BinarySearch(r.keys, hi)
+	if !found {
+		return
+	}
+	r.containers[i] = r.containers[i].remove(lo)
+	if r.containers[i].card() == 0 {
+		r.keys = slices.Delete(r.keys, i, i+1)
+		r.containers = slices.Delete(r.containers, i, i+1)
+	}
+}
+
+// Contains 是否包含元素
+func (r *Roaring) Contains(v uint32) bool {
+	hi, lo := split(v)
+	i, found := slices.BinarySearch(r.keys, hi)
+	return found && r.containers[i].contains(lo)
+}
+
+// Cardinality 返回元素个数
+func (r *Roaring) Cardinality() uint64 {
+	var n uint64
+	for _, c := range r.containers {
+		n += uint64(c.card())
+	}
+	return n
+}
+
+// IsEmpty 是否为空
+func (r *Roaring) IsEmpty() bool {
+	return len(r.keys) == 0
+}
+
+// Clear 清空
+func (r *Roaring) Clear() {
+	r.keys = nil
+	r.containers = nil
+}
+
+// Items 按从小到大的顺序返回所有元素
+func (r *Roaring) Items() []uint32 {
+	items := make([]uint32, 0, r.Cardinality())
+	for v := range r.All() {
+		items = append(items, v)
+	}
+	return items
+}
+
+// All 返回从小到大遍历所有元素的迭代器，遍历过程中不能修改 Roaring
+func (r *Roaring) All() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		for i, c := range r.containers {
+			hi := uint32(r.keys[i]) << 16
+			if !c.each(func(lo uint16) bool {
+				return yield(hi | uint32(lo))
+			}) {
+				return
+			}
+		}
+	}
+}
+
+// ToSet 转换为 Set[uint32]
+func (r *Roaring) ToSet() *Set[uint32] {
+	s := &Set[uint32]{container: make(map[uint32]struct{}, r.Cardinality())}
+	for v := range r.All() {
+		s.container[v] = struct{}{}
+	}
+	return s
+}
+
+// Clone 复制 Roaring
+func (r *Roaring) Clone() *Roaring {
+	res := &Roaring{
+		keys:       slices.Clone(r.keys),
+		containers: make([]container, len(r.containers)),
+	}
+	for i, c := range r.containers {
+		res.containers[i] = c.clone()
+	}
+	return res
+}
+
+// Equal 两个 Roaring 是否包含相同的元素，与容器的表示无关
+func (r *Roaring) Equal(other *Roaring) bool {
+	if !slices.Equal(r.keys, other.keys) {
+		return false
+	}
+	for i, c := range r.containers {
+		if c.card() != other.containers[i].card() {
+			return false
+		}
+		var vals []uint16
+		c.each(func(v uint16) bool {
+			vals = append(vals, v)
+			return true
+		})
+		j := 0
+		if !other.containers[i].each(func(v uint16) bool {
+			j++
+			return vals[j-1] == v
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// And 交集
+func (r *Roaring) And(other *Roaring) *Roaring {
+	return r.merge(other, containerAnd, false, false)
+}
+
+// Or 并集
+func (r *Roaring) Or(other *Roaring) *Roaring {
+	return r.merge(other, containerOr, true, true)
+}
+
+// AndNot 差集，即在 r 中但不在 other 中的元素
+func (r *Roaring) AndNot(other *Roaring) *Roaring {
+	return r.merge(other, containerAndNot, true, false)
+}
+
+// Xor 对称差集
+func (r *Roaring) Xor(other *Roaring) *Roaring {
+	return r.merge(other, containerXor, true, true)
+}
+
+// merge 按 key 归并两个 Roaring，key 相同的容器交给 op 计算，
+// keepX/keepY 表示只出现在 r 或 other 中的容器是否保留
+func (r *Roaring) merge(other *Roaring, op func(x, y container) container, keepX, keepY bool) *Roaring {
+	res := &Roaring{}
+	appendContainer := func(key uint16, c container) {
+		if c.card() > 0 {
+			res.keys = append(res.keys, key)
+			res.containers = append(res.containers, c)
+		}
+	}
+	i, j := 0, 0
+	for i < len(r.keys) && j < len(other.keys) {
+		switch {
+		case r.keys[i] < other.keys[j]:
+			if keepX {
+				appendContainer(r.keys[i], r.containers[i].clone())
+			}
+			i++
+		case r.keys[i] > other.keys[j]:
+			if keepY {
+				appendContainer(other.keys[j], other.containers[j].clone())
+			}
+			j++
+		default:
+			appendContainer(r.keys[i], op(r.containers[i], other.containers[j]))
+			i++
+			j++
+		}
+	}
+	for ; keepX && i < len(r.keys); i++ {
+		appendContainer(r.keys[i], r.containers[i].clone())
+	}
+	for ; keepY && j < len(other.keys); j++ {
+		appendContainer(other.keys[j], other.containers[j].clone())
+	}
+	return res
+}
+
+// RunOptimize 将每个容器转换为序列化后最小的表示，连续区间较多时能显著减少内存和序列化大小
+func (r *Roaring) RunOptimize() {
+	for i, c := range r.containers {
+		r.containers[i] = optimize(c)
+	}
+}
+
+// MarshalBinary 按 RoaringFormatSpec 编码
+func (r *Roaring) MarshalBinary() ([]byte, error) {
+	var buf []byte
+	n := len(r.keys)
+	hasRun := slices.ContainsFunc(r.containers, func(c container) bool {
+		_, ok := c.(*runContainer)
+		return ok
+	})
+
+	if hasRun {
+		buf = binary.LittleEndian.AppendUint32(buf, serialCookie|uint32(n-1)<<16)
+		runFlags := make([]byte, (n+7)/8)
+		for i, c := range r.containers {
+			if _, ok := c.(*runContainer); ok {
+				runFlags[i/8] |= 1 << (i % 8)
+			}
+		}
+		buf = append(buf, runFlags...)
+	} else {
+		buf = binary.LittleEndian.AppendUint32(buf, serialCookieNoRun)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(n))
+	}
+	for i, c := range r.containers {
+		buf = binary.LittleEndian.AppendUint16(buf, r.keys[i])
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(c.card()-1))
+	}
+	if !hasRun || n >= noOffsetThreshold {
+		offset := len(buf) + 4*n
+		for _, c := range r.containers {
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(offset))
+			offset += serializedSize(c)
+		}
+	}
+
+	for _, c := range r.containers {
+		switch c := c.(type) {
+		case *arrayContainer:
+			for _, v := range c.vals {
+				buf = binary.LittleEndian.AppendUint16(buf, v)
+			}
+		case *bitmapContainer:
+			for _, w := range c.words {
+				buf = binary.LittleEndian.AppendUint64(buf, w)
+			}
+		case *runContainer:
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(len(c.runs)))
+			for _, iv := range c.runs {
+				buf = binary.LittleEndian.AppendUint16(buf, iv.start)
+				buf = binary.LittleEndian.AppendUint16(buf, iv.last-iv.start)
+			}
+		}
+	}
+	return buf, nil
+}
+
+// WriteTo 按 RoaringFormatSpec 编码后写入 w
+func (r *Roaring) WriteTo(w io.Writer) (int64, error) {
+	data, _ := r.MarshalBinary()
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// UnmarshalBinary 解码 RoaringFormatSpec 格式的数据，会覆盖已有的元素
+func (r *Roaring) UnmarshalBinary(data []byte) error {
+	d := &roaringDecoder{data: data}
+	cookie := d.uint32()
+	var (
+		n        int
+		runFlags []byte
+	)
+	switch {
+	case cookie == serialCookieNoRun:
+		n = int(d.uint32())
+	case cookie&0xffff == serialCookie:
+		n = int(cookie>>16) + 1
+		runFlags = d.bytes((n + 7) / 8)
+	default:
+		return errRoaringFormat
+	}
+	if d.err != nil || n > 1<<16 {
+		return errRoaringFormat
+	}
+
+	keys := make([]uint16, n)
+	cards := make([]int, n)
+	for i := 0; i < n; i++ {
+		keys[i] = d.uint16()
+		cards[i] = int(d.uint16()) + 1
+		if i > 0 && keys[i] <= keys[i-1] {
+			return errRoaringFormat
+		}
+	}
+	if runFlags == nil || n >= noOffsetThreshold {
+		// 容器是连续存放的，不需要偏移量
+		d.bytes(4 * n)
+	}
+	if d.err != nil {
+		return errRoaringFormat
+	}
+
+	containers := make([]container, n)
+	for i := 0; i < n; i++ {
+		isRun := runFlags != nil && runFlags[i/8]&(1<<(i%8)) != 0
+		c, err := d.container(isRun, cards[i])
+		if err != nil {
+			return err
+		}
+		containers[i] = c
+	}
+	r.keys = keys
+	r.containers = containers
+	return nil
+}
+
+type roaringDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *roaringDecoder) bytes(n int) []byte {
+	if d.err != nil || len(d.data) < n {
+		d.err = errRoaringFormat
+		return nil
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b
+}
+
+func (d *roaringDecoder) uint16() uint16 {
+	if b := d.bytes(2); b != nil {
+		return binary.LittleEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *roaringDecoder) uint32() uint32 {
+	if b := d.bytes(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+// container 解码一个容器并校验其内容与头部声明的元素个数一致
+func (d *roaringDecoder) container(isRun bool, card int) (container, error) {
+	var c container
+	switch {
+	case isRun:
+		rc := &runContainer{runs: make([]interval16, d.uint16())}
+		for i := range rc.runs {
+			start, length := d.uint16(), d.uint16()
+			if int(start)+int(length) > 0xffff ||
+				(i > 0 && int(start) <= int(rc.runs[i-1].last)+1) {
+				return nil, errRoaringFormat
+			}
+			rc.runs[i] = interval16{start: start, last: start + length}
+		}
+		c = rc
+	case card <= arrayMaxSize:
+		ac := &arrayContainer{vals: make([]uint16, card)}
+		for i := range ac.vals {
+			ac.vals[i] = d.uint16()
+			if i > 0 && ac.vals[i] <= ac.vals[i-1] {
+				return nil, errRoaringFormat
+			}
+		}
+		c = ac
+	default:
+		bc := newBitmapContainer()
+		for i := range bc.words {
+			if b := d.bytes(8); b != nil {
+				bc.words[i] = binary.LittleEndian.Uint64(b)
+			}
+		}
+		c = bc.normalize()
+	}
+	if d.err != nil || c.card() != card {
+		return nil, errRoaringFormat
+	}
+	return c, nil
+}
+
+func split(v uint32) (hi, lo uint16) {
+	return uint16(v >> 16), uint16(v)
+}
@@ -0,0 +1,497 @@
+package set
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// roaring 的每个容器保存高 16 位相同的一组元素的低 16 位，有三种表示：
+//   - arrayContainer：有序数组，元素不超过 arrayMaxSize 个
+//   - bitmapContainer：65536 位的位图，元素超过 arrayMaxSize 个
+//   - runContainer：有序的连续区间，由 RunOptimize 或 AddRange 产生
+// 修改 runContainer 时会先将其转换为数组或位图
+
+const (
+	arrayMaxSize = 4096
+	bitmapWords  = 1 << 16 / wordBits
+)
+
+type container interface {
+	// add 和 remove 可能会转换容器的表示，返回修改后的容器
+	add(x uint16) container
+	remove(x uint16) container
+	contains(x uint16) bool
+	card() int
+	// each 按从小到大的顺序遍历，yield 返回 false 时停止并返回 false
+	each(yield func(uint16) bool) bool
+	clone() container
+}
+
+type arrayContainer struct {
+	vals []uint16
+}
+
+func (a *arrayContainer) add(x uint16) container {
+	i, found := slices.BinarySearch(a.vals, x)
+	if found {
+		return a
+	}
+	if len(a.vals) >= arrayMaxSize {
+		return a.toBitmap().add(x)
+	}
+	a.vals = slices.Insert(a.vals, i, x)
+	return a
+}
+
+func (a *arrayContainer) remove(x uint16) container {
+	if i, found := slices.BinarySearch(a.vals, x); found {
+		a.vals = slices.Delete(a.vals, i, i+1)
+	}
+	return a
+}
+
+func (a *arrayContainer) contains(x uint16) bool {
+	_, found := slices.BinarySearch(a.vals, x)
+	return found
+}
+
+func (a *arrayContainer) card() int {
+	return len(a.vals)
+}
+
+func (a *arrayContainer) each(yield func(uint16) bool) bool {
+	for _, v := range a.vals {
+		if !yield(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *arrayContainer) clone() container {
+	return &arrayContainer{vals: slices.Clone(a.vals)}
+}
+
+func (a *arrayContainer) toBitmap() *bitmapContainer {
+	b := newBitmapContainer()
+	for _, v := range a.vals {
+		b.words[v/wordBits] |= 1 << (v % wordBits)
+	}
+	b.n = len(a.vals)
+	return b
+}
+
+type bitmapContainer struct {
+	words []uint64
+	n     int
+}
+
+func newBitmapContainer() *bitmapContainer {
+	return &bitmapContainer{words: make([]uint64, bitmapWords)}
+}
+
+func (b *bitmapContainer) add(x uint16) container {
+	mask := uint64(1) << (x % wordBits)
+	if w := &b.words[x/wordBits]; *w&mask == 0 {
+		*w |= mask
+		b.n++
+	}
+	return b
+}
+
+func (b *bitmapContainer) remove(x uint16) container {
+	mask := uint64(1) << (x % wordBits)
+	if w := &b.words[x/wordBits]; *w&mask != 0 {
+		*w &^= mask
+		b.n--
+		if b.n <= arrayMaxSize {
+			return b.toArray()
+		}
+	}
+	return b
+}
+
+func (b *bitmapContainer) contains(x uint16) bool {
+	return b.words[x/wordBits]&(1<<(x%wordBits)) != 0
+}
+
+func (b *bitmapContainer) card() int {
+	return b.n
+}
+
+func (b *bitmapContainer) each(yield func(uint16) bool) bool {
+	for i, w := range b.words {
+		for w != 0 {
+			if !yield(uint16(i*wordBits + bits.TrailingZeros64(w))) {
+				return false
+			}
+			w &= w - 1
+		}
+	}
+	return true
+}
+
+func (b *bitmapContainer) clone() container {
+	return &bitmapContainer{words: slices.Clone(b.words), n: b.n}
+}
+
+func (b *bitmapContainer) toArray() *arrayContainer {
+	a := &arrayContainer{vals: make([]uint16, 0, b.n)}
+	b.each(func(v uint16) bool {
+		a.vals = append(a.vals, v)
+		return true
+	})
+	return a
+}
+
+// normalize 按位运算后重新统计元素个数，元素较少时转换为数组
+func (b *bitmapContainer) normalize() container {
+	b.n = 0
+	for _, w := range b.words {
+		b.n += bits.OnesCount64(w)
+	}
+	if b.n <= arrayMaxSize {
+		return b.toArray()
+	}
+	return b
+}
+
+// interval16 闭区间 [start, last]
+type interval16 struct {
+	start, last uint16
+}
+
+type runContainer struct {
+	runs []interval16
+}
+
+func (r *runContainer) add(x uint16) container {
+	if r.contains(x) {
+		return r
+	}
+	return r.toEfficient().add(x)
+}
+
+func (r *runContainer) remove(x uint16) container {
+	if !r.contains(x) {
+		return r
+	}
+	return r.toEfficient().remove(x)
+}
+
+func (r *runContainer) contains(x uint16) bool {
+	// 找到第一个 start > x 的区间，x 只可能落在它前一个区间中
+	i, _ := slices.BinarySearchFunc(r.runs, x, func(iv interval16, x uint16) int {
+		if iv.start <= x {
+			return -1
+		}
+		return 1
+	})
+	return i > 0 && x <= r.runs[i-1].last
+}
+
+func (r *runContainer) card() int {
+	n := 0
+	for _, iv := range r.runs {
+		n += int(iv.last-iv.start) + 1
+	}
+	return n
+}
+
+func (r *runContainer) each(yield func(uint16) bool) bool {
+	for _, iv := range r.runs {
+		for v := int(iv.start); v <= int(iv.last); v++ {
+			if !yield(uint16(v)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r *runContainer) clone() container {
+	return &runContainer{runs: slices.Clone(r.runs)}
+}
+
+// toEfficient 转换为数组或位图
+func (r *runContainer) toEfficient() container {
+	if r.card() <= arrayMaxSize {
+		a := &arrayContainer{vals: make([]uint16, 0, r.card())}
+		r.each(func(v uint16) bool {
+			a.vals = append(a.vals, v)
+			return true
+		})
+		return a
+	}
+	b := newBitmapContainer()
+	for _, iv := range r.runs {
+		for v := int(iv.start); v <= int(iv.last); v++ {
+			b.words[v/wordBits] |= 1 << (v % wordBits)
+		}
+	}
+	b.n = r.card()
+	return b
+}
+
+// serializedSize 容器在标准格式中占用的字节数
+func serializedSize(c container) int {
+	switch c := c.(type) {
+	case *arrayContainer:
+		return 2 * len(c.vals)
+	case *bitmapContainer:
+		return 8 * bitmapWords
+	case *runContainer:
+		return 2 + 4*len(c.runs)
+	}
+	return 0
+}
+
+// toRuns 将容器中的元素合并为连续区间
+func toRuns(c container) *runContainer {
+	r := &runContainer{}
+	c.each(func(v uint16) bool {
+		if n := len(r.runs); n > 0 && int(r.runs[n-1].last)+1 == int(v) {
+			r.runs[n-1].last = v
+		} else {
+			r.runs = append(r.runs, interval16{start: v, last: v})
+		}
+		return true
+	})
+	return r
+}
+
+// optimize 选择序列化后最小的表示
+func optimize(c container) container {
+	runs := toRuns(c)
+	var efficient container = c
+	if r, ok := c.(*runContainer); ok {
+		efficient = r.toEfficient()
+	}
+	if serializedSize(runs) < serializedSize(efficient) {
+		return runs
+	}
+	return efficient
+}
+
+// materialize 将 runContainer 转换为数组或位图，便于与其他容器做集合运算
+func materialize(c container) container {
+	if r, ok := c.(*runContainer); ok {
+		return r.toEfficient()
+	}
+	return c
+}
+
+func containerAnd(x, y container) container {
+	if rx, ok := x.(*runContainer); ok {
+		if ry, ok := y.(*runContainer); ok {
+			return intersectRuns(rx, ry)
+		}
+	}
+	x, y = materialize(x), materialize(y)
+	switch a := x.(type) {
+	case *arrayContainer:
+		switch b := y.(type) {
+		case *arrayContainer:
+			return mergeArrays(a.vals, b.vals, true, false, false)
+		case *bitmapContainer:
+			return filterArray(a, b, true)
+		}
+	case *bitmapContainer:
+		switch b := y.(type) {
+		case *arrayContainer:
+			return filterArray(b, a, true)
+		case *bitmapContainer:
+			res := newBitmapContainer()
+			for i := range res.words {
+				res.words[i] = a.words[i] & b.words[i]
+			}
+			return res.normalize()
+		}
+	}
+	return nil
+}
+
+func containerOr(x, y container) container {
+	if rx, ok := x.(*runContainer); ok {
+		if ry, ok := y.(*runContainer); ok {
+			return unionRuns(rx, ry)
+		}
+	}
+	x, y = materialize(x), materialize(y)
+	switch a := x.(type) {
+	case *arrayContainer:
+		switch b := y.(type) {
+		case *arrayContainer:
+			return toEfficientArray(mergeArrays(a.vals, b.vals, true, true, true))
+		case *bitmapContainer:
+			return orArrayBitmap(a, b)
+		}
+	case *bitmapContainer:
+		switch b := y.(type) {
+		case *arrayContainer:
+			return orArrayBitmap(b, a)
+		case *bitmapContainer:
+			res := newBitmapContainer()
+			for i := range res.words {
+				res.words[i] = a.words[i] | b.words[i]
+			}
+			return res.normalize()
+		}
+	}
+	return nil
+}
+
+func containerAndNot(x, y container) container {
+	x, y = materialize(x), materialize(y)
+	switch a := x.(type) {
+	case *arrayContainer:
+		switch b := y.(type) {
+		case *arrayContainer:
+			return mergeArrays(a.vals, b.vals, false, true, false)
+		case *bitmapContainer:
+			return filterArray(a, b, false)
+		}
+	case *bitmapContainer:
+		res := a.clone().(*bitmapContainer)
+		switch b := y.(type) {
+		case *arrayContainer:
+			for _, v := range b.vals {
+				res.words[v/wordBits] &^= 1 << (v % wordBits)
+			}
+		case *bitmapContainer:
+			for i := range res.words {
+				res.words[i] &^= b.words[i]
+			}
+		}
+		return res.normalize()
+	}
+	return nil
+}
+
+func containerXor(x, y container) container {
+	x, y = materialize(x), materialize(y)
+	switch a := x.(type) {
+	case *arrayContainer:
+		switch b := y.(type) {
+		case *arrayContainer:
+			return toEfficientArray(mergeArrays(a.vals, b.vals, false, true, true))
+		case *bitmapContainer:
+			return xorArrayBitmap(a, b)
+		}
+	case *bitmapContainer:
+		switch b := y.(type) {
+		case *arrayContainer:
+			return xorArrayBitmap(b, a)
+		case *bitmapContainer:
+			res := newBitmapContainer()
+			for i := range res.words {
+				res.words[i] = a.words[i] ^ b.words[i]
+			}
+			return res.normalize()
+		}
+	}
+	return nil
+}
+
+// mergeArrays 归并两个有序数组，both/onlyX/onlyY 分别表示是否保留两者共有、只在 x 中、只在 y 中的元素
+func mergeArrays(x, y []uint16, both, onlyX, onlyY bool) *arrayContainer {
+	res := make([]uint16, 0, max(len(x), len(y)))
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] < y[j]:
+			if onlyX {
+				res = append(res, x[i])
+			}
+			i++
+		case x[i] > y[j]:
+			if onlyY {
+				res = append(res, y[j])
+			}
+			j++
+		default:
+			if both {
+				res = append(res, x[i])
+			}
+			i++
+			j++
+		}
+	}
+	if onlyX {
+		res = append(res, x[i:]...)
+	}
+	if onlyY {
+		res = append(res, y[j:]...)
+	}
+	return &arrayContainer{vals: res}
+}
+
+// toEfficientArray 元素超过 arrayMaxSize 时转换为位图
+func toEfficientArray(a *arrayContainer) container {
+	if len(a.vals) > arrayMaxSize {
+		return a.toBitmap()
+	}
+	return a
+}
+
+// filterArray 保留 a 中在（keep 为 true）或不在 b 中的元素
+func filterArray(a *arrayContainer, b *bitmapContainer, keep bool) *arrayContainer {
+	res := make([]uint16, 0, len(a.vals))
+	for _, v := range a.vals {
+		if b.contains(v) == keep {
+			res = append(res, v)
+		}
+	}
+	return &arrayContainer{vals: res}
+}
+
+func orArrayBitmap(a *arrayContainer, b *bitmapContainer) container {
+	res := b.clone().(*bitmapContainer)
+	for _, v := range a.vals {
+		res.add(v)
+	}
+	return res
+}
+
+func xorArrayBitmap(a *arrayContainer, b *bitmapContainer) container {
+	res := b.clone().(*bitmapContainer)
+	for _, v := range a.vals {
+		res.words[v/wordBits] ^= 1 << (v % wordBits)
+	}
+	return res.normalize()
+}
+
+func intersectRuns(x, y *runContainer) container {
+	res := &runContainer{}
+	i, j := 0, 0
+	for i < len(x.runs) && j < len(y.runs) {
+		a, b := x.runs[i], y.runs[j]
+		if start, last := max(a.start, b.start), min(a.last, b.last); start <= last {
+			res.runs = append(res.runs, interval16{start: start, last: last})
+		}
+		if a.last < b.last {
+			i++
+		} else {
+			j++
+		}
+	}
+	return optimize(res)
+}
+
+func unionRuns(x, y *runContainer) container {
+	merged := make([]interval16, 0, len(x.runs)+len(y.runs))
+	merged = append(merged, x.runs...)
+	merged = append(merged, y.runs...)
+	slices.SortFunc(merged, func(a, b interval16) int {
+		return int(a.start) - int(b.start)
+	})
+	res := &runContainer{}
+	for _, iv := range merged {
+		if n := len(res.runs); n > 0 && int(iv.start) <= int(res.runs[n-1].last)+1 {
+			res.runs[n-1].last = max(res.runs[n-1].last, iv.last)
+		} else {
+			res.runs = append(res.runs, iv)
+		}
+	}
+	return optimize(res)
+}
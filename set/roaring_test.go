@@ -0,0 +1,322 @@
+package set
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestRoaringBasicOperations(t *testing.T) {
+	r := NewRoaring(1, 70000, 5, 1<<31)
+
+	if r.Cardinality() != 4 {
+		t.Errorf("expected cardinality 4, got %d", r.Cardinality())
+	}
+	if !r.Contains(70000) || r.Contains(2) || r.Contains(1<<31+1) {
+		t.Errorf("contains failed")
+	}
+	if !reflect.DeepEqual(r.Items(), []uint32{1, 5, 70000, 1 << 31}) {
+		t.Errorf("expected sorted items, got %v", r.Items())
+	}
+
+	r.Remove(70000)
+	r.Remove(12345)
+	if r.Cardinality() != 3 || r.Contains(70000) || len(r.keys) != 2 {
+		t.Errorf("remove failed, items=%v containers=%d", r.Items(), len(r.keys))
+	}
+
+	r.Clear()
+	if !r.IsEmpty() || r.Cardinality() != 0 {
+		t.Errorf("clear failed")
+	}
+}
+
+func TestRoaringContainerConversion(t *testing.T) {
+	r := NewRoaring()
+	for i := uint32(0); i < 5000; i++ {
+		r.Add(i * 2)
+	}
+	if _, ok := r.containers[0].(*bitmapContainer); !ok {
+		t.Errorf("expected bitmap container after %d elements, got %T", arrayMaxSize, r.containers[0])
+	}
+	for i := uint32(0); i < 1000; i++ {
+		r.Remove(i * 2)
+	}
+	if _, ok := r.containers[0].(*arrayContainer); !ok {
+		t.Errorf("expected array container after removal, got %T", r.containers[0])
+	}
+	if r.Cardinality() != 4000 {
+		t.Errorf("expected cardinality 4000, got %d", r.Cardinality())
+	}
+
+	// 连续区间经过 RunOptimize 后变为区间容器
+	d := NewRoaring()
+	d.AddRange(100, 60000)
+	d.Add(65535)
+	d.RunOptimize()
+	if _, ok := d.containers[0].(*runContainer); !ok {
+		t.Errorf("expected run container, got %T", d.containers[0])
+	}
+	if d.Cardinality() != 59902 || !d.Contains(60000) || d.Contains(60001) || !d.Contains(65535) {
+		t.Errorf("run container contents wrong, cardinality=%d", d.Cardinality())
+	}
+	d.Remove(100)
+	if d.Contains(100) || d.Cardinality() != 59901 {
+		t.Errorf("remove from run container failed")
+	}
+}
+
+func TestRoaringAddRange(t *testing.T) {
+	r := NewRoaring(5)
+	r.AddRange(65530, 65545)
+	r.AddRange(10, 9)
+
+	want := []uint32{5}
+	for v := uint32(65530); v <= 65545; v++ {
+		want = append(want, v)
+	}
+	if !reflect.DeepEqual(r.Items(), want) {
+		t.Errorf("add range failed, got %v", r.Items())
+	}
+
+	// 跨越多个容器：两端与已有容器合并，中间完整覆盖的容器替换为区间容器，区间外的容器不受影响
+	m := NewRoaring(1, 1<<16|7, 3<<16|9, 5<<16)
+	m.AddRange(1<<16|0xfff0, 4<<16|5)
+	want = []uint32{1, 1<<16 | 7}
+	for v := uint32(1<<16 | 0xfff0); v <= 4<<16|5; v++ {
+		want = append(want, v)
+	}
+	want = append(want, 5<<16)
+	if !reflect.DeepEqual(m.Items(), want) {
+		t.Errorf("add range across containers failed, cardinality=%d", m.Cardinality())
+	}
+	if !reflect.DeepEqual(m.keys, []uint16{0, 1, 2, 3, 4, 5}) {
+		t.Errorf("unexpected keys %v", m.keys)
+	}
+	for _, i := range []int{2, 3} {
+		if c, ok := m.containers[i].(*runContainer); !ok || len(c.runs) != 1 {
+			t.Errorf("expected full run container at %d, got %T", i, m.containers[i])
+		}
+	}
+
+	full := NewRoaring()
+	full.AddRange(1<<32-3, 1<<32-1)
+	if !reflect.DeepEqual(full.Items(), []uint32{1<<32 - 3, 1<<32 - 2, 1<<32 - 1}) {
+		t.Errorf("add range at the end failed, got %v", full.Items())
+	}
+	full.AddRange(0, 1<<32-1)
+	if full.Cardinality() != 1<<32 || len(full.keys) != 1<<16 {
+		t.Errorf("add full range failed, cardinality=%d", full.Cardinality())
+	}
+}
+
+func TestRoaringOperations(t *testing.T) {
+	a := NewRoaring(1, 2, 3, 100000)
+	a.AddRange(200000, 210000)
+	b := NewRoaring(3, 4, 100000)
+	b.AddRange(205000, 215000)
+
+	sa, sb := a.ToSet(), b.ToSet()
+	tests := []struct {
+		name string
+		got  *Roaring
+		want *Set[uint32]
+	}{
+		{"and", a.And(b), sa.Intersect(sb)},
+		{"or", a.Or(b), sa.Union(sb)},
+		{"and not", a.AndNot(b), sa.Difference(sb)},
+		{"xor", a.Xor(b), sa.SymmetricDifference(sb)},
+	}
+	for _, tt := range tests {
+		if !compareSets(tt.got.ToSet(), tt.want) {
+			t.Errorf("%s: expected cardinality %d, got %d", tt.name, tt.want.Len(), tt.got.Cardinality())
+		}
+	}
+
+	c := a.Clone()
+	c.RunOptimize()
+	if !a.Equal(c) || a.Equal(b) {
+		t.Errorf("equal should not depend on container type")
+	}
+	c.Add(7)
+	if a.Contains(7) {
+		t.Errorf("clone should not share storage")
+	}
+}
+
+func TestRoaringPortableFormat(t *testing.T) {
+	// 不含区间容器：cookie、容器数、key 与基数、偏移量、数组内容
+	want := []byte{
+		0x3a, 0x30, 0, 0, 1, 0, 0, 0,
+		0, 0, 2, 0,
+		16, 0, 0, 0,
+		1, 0, 2, 0, 3, 0,
+	}
+	data, err := NewRoaring(1, 2, 3).MarshalBinary()
+	if err != nil || !bytes.Equal(data, want) {
+		t.Errorf("expected %v, got %v (%v)", want, data, err)
+	}
+
+	// 含区间容器且容器数小于 4 时没有偏移量
+	r := NewRoaring()
+	r.AddRange(10, 1000)
+	r.RunOptimize()
+	want = []byte{
+		0x3b, 0x30, 0, 0, 1,
+		0, 0, 0xde, 0x03,
+		1, 0, 10, 0, 0xde, 0x03,
+	}
+	data, err = r.MarshalBinary()
+	if err != nil || !bytes.Equal(data, want) {
+		t.Errorf("expected %v, got %v (%v)", want, data, err)
+	}
+}
+
+func TestRoaringBinaryRoundTrip(t *testing.T) {
+	r := NewRoaring(1, 2, 3, 1<<20, 1<<31)
+	r.AddRange(1<<17, 1<<17+10000)
+	r.AddRange(5<<16, 5<<16+100)
+	for i := uint32(0); i < 6000; i++ {
+		r.Add(9<<16 + i*7)
+	}
+	for _, optimize := range []bool{false, true} {
+		if optimize {
+			r.RunOptimize()
+		}
+		var buf bytes.Buffer
+		if _, err := r.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		got := NewRoaring(42)
+		if err := got.UnmarshalBinary(buf.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(r) {
+			t.Errorf("round trip (optimize=%v) failed, cardinality %d != %d", optimize, got.Cardinality(), r.Cardinality())
+		}
+	}
+
+	for _, data := range [][]byte{nil, {1, 2, 3, 4}, {0x3a, 0x30, 0, 0, 1, 0, 0, 0, 0, 0}} {
+		if err := NewRoaring().UnmarshalBinary(data); err == nil {
+			t.Errorf("expected error for %v", data)
+		}
+	}
+}
+
+// roaringFromOps 按 data 中每 4 个字节一条指令构造两个 Roaring，并同步修改对照的 Set
+func roaringFromOps(data []byte) (a, b *Roaring, sa, sb *Set[uint32]) {
+	a, b = NewRoaring(), NewRoaring()
+	sa, sb = NewSet[uint32](), NewSet[uint32]()
+	for ; len(data) >= 4; data = data[4:] {
+		r, s := a, sa
+		if data[0]&0x80 != 0 {
+			r, s = b, sb
+		}
+		// 高 16 位只取 0~3，保证两个 Roaring 的容器有重叠
+		v := uint32(data[1]%4)<<16 | uint32(data[2])<<8 | uint32(data[3])
+		switch data[0] % 4 {
+		case 0, 1:
+			r.Add(v)
+			s.Add(v)
+		case 2:
+			r.Remove(v)
+			s.Delete(v)
+		case 3:
+			// 区间足够长时容器会变为位图
+			hi := v + uint32(data[3])*64
+			r.AddRange(v, hi)
+			for x := v; x <= hi; x++ {
+				s.Add(x)
+			}
+		}
+		if data[0]&0x40 != 0 {
+			r.RunOptimize()
+		}
+	}
+	return a, b, sa, sb
+}
+
+func FuzzRoaring(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 1, 0x80, 0, 0, 1})
+	f.Add([]byte{3, 0, 0, 255, 0x83, 0, 128, 200, 2, 0, 10, 10, 0x43, 1, 0, 0})
+	f.Add([]byte{3, 1, 0, 255, 0xc3, 1, 0, 255, 0x82, 1, 1, 1, 0x40, 2, 3, 4})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		a, b, sa, sb := roaringFromOps(data)
+
+		for _, tc := range []struct {
+			name string
+			got  *Roaring
+			want *Set[uint32]
+		}{
+			{"a", a, sa},
+			{"b", b, sb},
+			{"and", a.And(b), sa.Intersect(sb)},
+			{"or", a.Or(b), sa.Union(sb)},
+			{"and not", a.AndNot(b), sa.Difference(sb)},
+			{"xor", a.Xor(b), sa.SymmetricDifference(sb)},
+		} {
+			items := tc.want.Items()
+			slices.Sort(items)
+			if got := tc.got.Items(); !slices.Equal(got, items) {
+				t.Fatalf("%s: expected %d items, got %d", tc.name, len(items), len(got))
+			}
+			if tc.got.Cardinality() != uint64(len(items)) {
+				t.Fatalf("%s: cardinality %d != %d", tc.name, tc.got.Cardinality(), len(items))
+			}
+			for _, c := range tc.got.containers {
+				if c.card() == 0 {
+					t.Fatalf("%s: empty container", tc.name)
+				}
+			}
+
+			data, _ := tc.got.MarshalBinary()
+			decoded := NewRoaring()
+			if err := decoded.UnmarshalBinary(data); err != nil {
+				t.Fatalf("%s: unmarshal: %v", tc.name, err)
+			}
+			if !decoded.Equal(tc.got) {
+				t.Fatalf("%s: round trip mismatch", tc.name)
+			}
+		}
+	})
+}
+
+func FuzzRoaringUnmarshal(f *testing.F) {
+	for _, r := range []*Roaring{NewRoaring(1, 2, 3), NewRoaring(1, 1<<20)} {
+		data, _ := r.MarshalBinary()
+		f.Add(data)
+		r.AddRange(100, 5000)
+		r.RunOptimize()
+		data, _ = r.MarshalBinary()
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		r := NewRoaring()
+		if err := r.UnmarshalBinary(data); err != nil {
+			return
+		}
+		// 能成功解码的数据再次编码后内容不变
+		again, _ := r.MarshalBinary()
+		r2 := NewRoaring()
+		if err := r2.UnmarshalBinary(again); err != nil || !r2.Equal(r) {
+			t.Fatalf("re-encode mismatch: %v", err)
+		}
+		if uint64(len(r.Items())) != r.Cardinality() {
+			t.Fatalf("cardinality mismatch")
+		}
+	})
+}
+
+func BenchmarkRoaringAnd(b *testing.B) {
+	x, y := NewRoaring(), NewRoaring()
+	for i := uint32(0); i < 1<<20; i += 3 {
+		x.Add(i)
+	}
+	y.AddRange(1<<18, 1<<19)
+	for b.Loop() {
+		x.And(y)
+	}
+}
@@ -1,5 +1,7 @@
 package set
 
+import "iter"
+
 // 通过map实现的set数据结构
 // 不是并发安全的！！！
 
@@ -13,6 +15,20 @@ func NewSet[T comparable]() *Set[T] {
 	}
 }
 
+// NewSetFrom 创建包含 elems 的 set
+func NewSetFrom[T comparable](elems ...T) *Set[T] {
+	return FromSlice(elems)
+}
+
+// FromSlice 由切片创建 set，重复的元素只保留一个
+func FromSlice[T comparable](slice []T) *Set[T] {
+	s := &Set[T]{
+		container: make(map[T]struct{}, len(slice)),
+	}
+	s.Add(slice...)
+	return s
+}
+
 // Add 添加元素
 func (s *Set[T]) Add(elems ...T) {
 	for _, elem := range elems {
@@ -50,24 +66,36 @@ func (s *Set[T]) Items() []T {
 	return keys
 }
 
+// All 返回遍历 set 中所有元素的迭代器，不会复制元素
+func (s *Set[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for key := range s.container {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
 // Union 并集
 func (s *Set[T]) Union(other *Set[T]) *Set[T] {
-	result := NewSet[T]()
-	for _, v := range s.Items() {
-		result.Add(v)
-	}
-	for _, v := range other.Items() {
-		result.Add(v)
+	result := s.Clone()
+	for v := range other.container {
+		result.container[v] = struct{}{}
 	}
 	return result
 }
 
-// Intersect 交集
+// Intersect 交集，遍历两者中较小的 set
 func (s *Set[T]) Intersect(other *Set[T]) *Set[T] {
+	small, large := s, other
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
 	result := NewSet[T]()
-	for _, v := range s.Items() {
-		if other.Contains(v) {
-			result.Add(v)
+	for v := range small.container {
+		if large.Contains(v) {
+			result.container[v] = struct{}{}
 		}
 	}
 	return result
@@ -76,9 +104,138 @@ func (s *Set[T]) Intersect(other *Set[T]) *Set[T] {
 // Difference 差集
 func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
 	result := NewSet[T]()
-	for _, v := range s.Items() {
+	for v := range s.container {
+		if !other.Contains(v) {
+			result.container[v] = struct{}{}
+		}
+	}
+	return result
+}
+
+// SymmetricDifference 对称差集，即只在其中一个 set 中出现的元素
+func (s *Set[T]) SymmetricDifference(other *Set[T]) *Set[T] {
+	result := s.Difference(other)
+	for v := range other.container {
+		if !s.Contains(v) {
+			result.container[v] = struct{}{}
+		}
+	}
+	return result
+}
+
+// IsSubset s 是否为 other 的子集
+func (s *Set[T]) IsSubset(other *Set[T]) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+	for v := range s.container {
 		if !other.Contains(v) {
-			result.Add(v)
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset s 是否为 other 的超集
+func (s *Set[T]) IsSuperset(other *Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+// IsDisjoint 两个 set 是否没有公共元素，遍历两者中较小的 set
+func (s *Set[T]) IsDisjoint(other *Set[T]) bool {
+	small, large := s, other
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+	for v := range small.container {
+		if large.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal 两个 set 是否包含相同的元素
+func (s *Set[T]) Equal(other *Set[T]) bool {
+	return s.Len() == other.Len() && s.IsSubset(other)
+}
+
+// Clone 复制 set
+func (s *Set[T]) Clone() *Set[T] {
+	result := &Set[T]{
+		container: make(map[T]struct{}, len(s.container)),
+	}
+	for v := range s.container {
+		result.container[v] = struct{}{}
+	}
+	return result
+}
+
+// Pop 删除并返回任意一个元素，set 为空时返回 false
+func (s *Set[T]) Pop() (T, bool) {
+	for v := range s.container {
+		delete(s.container, v)
+		return v, true
+	}
+	var zero T
+	return zero, false
+}
+
+// Filter 返回满足 fn 的元素组成的新 set
+func (s *Set[T]) Filter(fn func(T) bool) *Set[T] {
+	result := NewSet[T]()
+	for v := range s.container {
+		if fn(v) {
+			result.container[v] = struct{}{}
+		}
+	}
+	return result
+}
+
+// Map 将 set 中的元素逐个转换后组成新的 set，转换结果相同的元素会合并
+func Map[T, U comparable](s *Set[T], fn func(T) U) *Set[U] {
+	result := &Set[U]{
+		container: make(map[U]struct{}, len(s.container)),
+	}
+	for v := range s.container {
+		result.container[fn(v)] = struct{}{}
+	}
+	return result
+}
+
+// UnionAll 多个 set 的并集
+func UnionAll[T comparable](sets ...*Set[T]) *Set[T] {
+	result := NewSet[T]()
+	for _, s := range sets {
+		for v := range s.container {
+			result.container[v] = struct{}{}
+		}
+	}
+	return result
+}
+
+// IntersectAll 多个 set 的交集，遍历其中最小的 set，未传入 set 时返回空 set
+func IntersectAll[T comparable](sets ...*Set[T]) *Set[T] {
+	if len(sets) == 0 {
+		return NewSet[T]()
+	}
+	smallest := sets[0]
+	for _, s := range sets[1:] {
+		if s.Len() < smallest.Len() {
+			smallest = s
+		}
+	}
+	result := NewSet[T]()
+	for v := range smallest.container {
+		in := true
+		for _, s := range sets {
+			if s != smallest && !s.Contains(v) {
+				in = false
+				break
+			}
+		}
+		if in {
+			result.container[v] = struct{}{}
 		}
 	}
 	return result
@@ -49,6 +49,31 @@ func TestSetItems(t *testing.T) {
 	}
 }
 
+func TestSetAll(t *testing.T) {
+	s := NewSet[string]()
+	s.Add("a", "b", "c")
+
+	var items []string
+	for v := range s.All() {
+		items = append(items, v)
+	}
+	sort.Strings(items)
+	expected := []string{"a", "b", "c"}
+
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("expected items %v, got %v", expected, items)
+	}
+
+	var visited int
+	for range s.All() {
+		visited++
+		break
+	}
+	if visited != 1 {
+		t.Errorf("expected break after 1 item, visited %d", visited)
+	}
+}
+
 func TestSetUnion(t *testing.T) {
 	a := NewSet[int]()
 	b := NewSet[int]()
@@ -97,6 +122,127 @@ func TestSetDifference(t *testing.T) {
 	}
 }
 
+func TestSetFrom(t *testing.T) {
+	s := NewSetFrom(1, 2, 2, 3)
+	if !compareSets(s, FromSlice([]int{3, 2, 1, 1})) || s.Len() != 3 {
+		t.Errorf("NewSetFrom/FromSlice failed, got %v", s.Items())
+	}
+	if NewSetFrom[int]().Len() != 0 {
+		t.Errorf("expected empty set")
+	}
+}
+
+func TestSetIntersectSmallerSide(t *testing.T) {
+	small := NewSetFrom(1, 2)
+	large := NewSetFrom(0, 1, 2, 3, 4, 5, 6, 7, 8, 9)
+	expected := NewSetFrom(1, 2)
+
+	if !compareSets(small.Intersect(large), expected) || !compareSets(large.Intersect(small), expected) {
+		t.Errorf("intersect should not depend on receiver size")
+	}
+}
+
+func TestSetSymmetricDifference(t *testing.T) {
+	a := NewSetFrom(1, 2, 3)
+	b := NewSetFrom(3, 4)
+
+	got := a.SymmetricDifference(b)
+	if !compareSets(got, NewSetFrom(1, 2, 4)) {
+		t.Errorf("symmetric difference failed, got %v", got.Items())
+	}
+}
+
+func TestSetRelations(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     *Set[int]
+		subset   bool
+		superset bool
+		disjoint bool
+		equal    bool
+	}{
+		{"subset", NewSetFrom(1, 2), NewSetFrom(1, 2, 3), true, false, false, false},
+		{"superset", NewSetFrom(1, 2, 3), NewSetFrom(2), false, true, false, false},
+		{"equal", NewSetFrom(1, 2), NewSetFrom(2, 1), true, true, false, true},
+		{"disjoint", NewSetFrom(1, 2), NewSetFrom(3), false, false, true, false},
+		{"overlap", NewSetFrom(1, 2), NewSetFrom(2, 3), false, false, false, false},
+		{"empty", NewSet[int](), NewSet[int](), true, true, true, true},
+	}
+	for _, tt := range tests {
+		if got := tt.a.IsSubset(tt.b); got != tt.subset {
+			t.Errorf("%s: IsSubset expected %v, got %v", tt.name, tt.subset, got)
+		}
+		if got := tt.a.IsSuperset(tt.b); got != tt.superset {
+			t.Errorf("%s: IsSuperset expected %v, got %v", tt.name, tt.superset, got)
+		}
+		if got := tt.a.IsDisjoint(tt.b); got != tt.disjoint {
+			t.Errorf("%s: IsDisjoint expected %v, got %v", tt.name, tt.disjoint, got)
+		}
+		if got := tt.a.Equal(tt.b); got != tt.equal {
+			t.Errorf("%s: Equal expected %v, got %v", tt.name, tt.equal, got)
+		}
+	}
+}
+
+func TestSetCloneAndPop(t *testing.T) {
+	s := NewSetFrom(1, 2)
+	c := s.Clone()
+	c.Add(3)
+	if s.Len() != 2 || c.Len() != 3 {
+		t.Errorf("clone should not share storage, len=%d clone len=%d", s.Len(), c.Len())
+	}
+
+	seen := NewSet[int]()
+	for s.Len() > 0 {
+		v, ok := s.Pop()
+		if !ok || seen.Contains(v) {
+			t.Errorf("pop returned %v, %v", v, ok)
+		}
+		seen.Add(v)
+	}
+	if _, ok := s.Pop(); ok {
+		t.Errorf("expected pop on empty set to fail")
+	}
+	if !compareSets(seen, NewSetFrom(1, 2)) {
+		t.Errorf("pop returned %v", seen.Items())
+	}
+}
+
+func TestSetFilterAndMap(t *testing.T) {
+	s := NewSetFrom(1, 2, 3, 4)
+
+	even := s.Filter(func(v int) bool { return v%2 == 0 })
+	if !compareSets(even, NewSetFrom(2, 4)) {
+		t.Errorf("filter failed, got %v", even.Items())
+	}
+
+	parity := Map(s, func(v int) string {
+		if v%2 == 0 {
+			return "even"
+		}
+		return "odd"
+	})
+	if !compareSets(parity, NewSetFrom("even", "odd")) {
+		t.Errorf("map failed, got %v", parity.Items())
+	}
+}
+
+func TestSetUnionAllIntersectAll(t *testing.T) {
+	a := NewSetFrom(1, 2, 3, 4)
+	b := NewSetFrom(2, 3, 4)
+	c := NewSetFrom(3, 4, 5)
+
+	if got := UnionAll(a, b, c); !compareSets(got, NewSetFrom(1, 2, 3, 4, 5)) {
+		t.Errorf("union all failed, got %v", got.Items())
+	}
+	if got := IntersectAll(a, b, c); !compareSets(got, NewSetFrom(3, 4)) {
+		t.Errorf("intersect all failed, got %v", got.Items())
+	}
+	if UnionAll[int]().Len() != 0 || IntersectAll[int]().Len() != 0 {
+		t.Errorf("expected empty set without arguments")
+	}
+}
+
 // 辅助函数：比较两个 set 是否相等
 func compareSets[T comparable](a, b *Set[T]) bool {
 	if a.Len() != b.Len() {
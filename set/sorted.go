@@ -0,0 +1,467 @@
+package set
+
+import (
+	"cmp"
+	"iter"
+	"math/rand/v2"
+)
+
+// 基于跳表实现的有序 set，元素按比较函数从小到大排列，
+// 查找、插入、删除以及 Rank/At 的期望复杂度均为 O(log n)。
+// 不是并发安全的！！！
+
+const (
+	skipMaxLevel = 32
+	// skipP 每个节点出现在上一层的概率为 1/4
+	skipP = 4
+)
+
+type skipNode[T any] struct {
+	val  T
+	prev *skipNode[T]
+	next []skipLink[T]
+}
+
+// skipLink span 为该链接在最底层跨过的节点数，用于计算排名
+type skipLink[T any] struct {
+	node *skipNode[T]
+	span int
+}
+
+type SortedSet[T any] struct {
+	head   *skipNode[T]
+	tail   *skipNode[T]
+	level  int
+	length int
+	cmp    func(a, b T) int
+}
+
+// NewSortedSet 创建按自然顺序排列的 SortedSet
+func NewSortedSet[T cmp.Ordered](elems ...T) *SortedSet[T] {
+	s := NewSortedSetFunc(cmp.Compare[T])
+	s.Add(elems...)
+	return s
+}
+
+// NewSortedSetFunc 创建按 cmp 排列的 SortedSet，cmp(a, b) 返回 0 的元素视为同一个元素
+func NewSortedSetFunc[T any](cmp func(a, b T) int) *SortedSet[T] {
+	return &SortedSet[T]{
+		head:  &skipNode[T]{next: make([]skipLink[T], skipMaxLevel)},
+		level: 1,
+		cmp:   cmp,
+	}
+}
+
+// Add 添加元素
+func (s *SortedSet[T]) Add(elems ...T) {
+	for _, elem := range elems {
+		s.insert(elem)
+	}
+}
+
+// AddIfAbsent 元素不存在时添加，返回是否为新添加的元素
+func (s *SortedSet[T]) AddIfAbsent(elem T) bool {
+	return s.insert(elem)
+}
+
+// Delete 删除元素
+func (s *SortedSet[T]) Delete(elem T) {
+	var update [skipMaxLevel]*skipNode[T]
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for next := x.next[i].node; next != nil && s.cmp(next.val, elem) < 0; next = x.next[i].node {
+			x = next
+		}
+		update[i] = x
+	}
+	x = x.next[0].node
+	if x == nil || s.cmp(x.val, elem) != 0 {
+		return
+	}
+
+	for i := 0; i < s.level; i++ {
+		if update[i].next[i].node == x {
+			update[i].next[i].span += x.next[i].span - 1
+			update[i].next[i].node = x.next[i].node
+		} else {
+			update[i].next[i].span--
+		}
+	}
+	if next := x.next[0].node; next != nil {
+		next.prev = x.prev
+	} else {
+		s.tail = x.prev
+	}
+	for s.level > 1 && s.head.next[s.level-1].node == nil {
+		s.level--
+	}
+	s.length--
+}
+
+// Clear 清空set
+func (s *SortedSet[T]) Clear() {
+	s.head = &skipNode[T]{next: make([]skipLink[T], skipMaxLevel)}
+	s.tail = nil
+	s.level = 1
+	s.length = 0
+}
+
+// Len 统计set长度
+func (s *SortedSet[T]) Len() int {
+	return s.length
+}
+
+// Contains 是否包含元素
+func (s *SortedSet[T]) Contains(elem T) bool {
+	x := s.lower(elem).next[0].node
+	return x != nil && s.cmp(x.val, elem) == 0
+}
+
+// Items 按从小到大的顺序返回所有元素
+func (s *SortedSet[T]) Items() []T {
+	items := make([]T, 0, s.length)
+	for x := s.head.next[0].node; x != nil; x = x.next[0].node {
+		items = append(items, x.val)
+	}
+	return items
+}
+
+// All 返回从小到大遍历所有元素的迭代器，遍历过程中不能修改 set
+func (s *SortedSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for x := s.head.next[0].node; x != nil; x = x.next[0].node {
+			if !yield(x.val) {
+				return
+			}
+		}
+	}
+}
+
+// Backward 返回从大到小遍历所有元素的迭代器，遍历过程中不能修改 set
+func (s *SortedSet[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for x := s.tail; x != nil; x = x.prev {
+			if !yield(x.val) {
+				return
+			}
+		}
+	}
+}
+
+// Min 返回最小的元素，set 为空时返回 false
+func (s *SortedSet[T]) Min() (T, bool) {
+	return nodeVal(s.head.next[0].node)
+}
+
+// Max 返回最大的元素，set 为空时返回 false
+func (s *SortedSet[T]) Max() (T, bool) {
+	return nodeVal(s.tail)
+}
+
+// Floor 返回小于等于 elem 的最大元素
+func (s *SortedSet[T]) Floor(elem T) (T, bool) {
+	x := s.lower(elem)
+	if next := x.next[0].node; next != nil && s.cmp(next.val, elem) == 0 {
+		return next.val, true
+	}
+	if x == s.head {
+		return nodeVal[T](nil)
+	}
+	return x.val, true
+}
+
+// Ceiling 返回大于等于 elem 的最小元素
+func (s *SortedSet[T]) Ceiling(elem T) (T, bool) {
+	return nodeVal(s.lower(elem).next[0].node)
+}
+
+// RangeBetween 按从小到大的顺序返回 [lo, hi] 区间内的元素
+func (s *SortedSet[T]) RangeBetween(lo, hi T) []T {
+	var items []T
+	for x := s.lower(lo).next[0].node; x != nil && s.cmp(x.val, hi) <= 0; x = x.next[0].node {
+		items = append(items, x.val)
+	}
+	return items
+}
+
+// Rank 返回小于 elem 的元素个数，elem 存在时即为其从 0 开始的下标
+func (s *SortedSet[T]) Rank(elem T) int {
+	rank := 0
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for next := x.next[i].node; next != nil && s.cmp(next.val, elem) < 0; next = x.next[i].node {
+			rank += x.next[i].span
+			x = next
+		}
+	}
+	return rank
+}
+
+// At 返回从小到大第 i 个（从 0 开始）元素
+func (s *SortedSet[T]) At(i int) (T, bool) {
+	if i < 0 || i >= s.length {
+		return nodeVal[T](nil)
+	}
+	// 最底层中第 i 个元素距离 head 为 i+1 步
+	target, traversed := i+1, 0
+	x := s.head
+	for lvl := s.level - 1; lvl >= 0; lvl-- {
+		for x.next[lvl].node != nil && traversed+x.next[lvl].span <= target {
+			traversed += x.next[lvl].span
+			x = x.next[lvl].node
+		}
+		if traversed == target {
+			return x.val, true
+		}
+	}
+	return nodeVal[T](nil)
+}
+
+// Pop 删除并返回最小的元素，set 为空时返回 false
+func (s *SortedSet[T]) Pop() (T, bool) {
+	val, ok := s.Min()
+	if ok {
+		s.Delete(val)
+	}
+	return val, ok
+}
+
+// Clone 复制 set
+func (s *SortedSet[T]) Clone() *SortedSet[T] {
+	result := NewSortedSetFunc(s.cmp)
+	for x := s.head.next[0].node; x != nil; x = x.next[0].node {
+		result.insert(x.val)
+	}
+	return result
+}
+
+// Union 并集
+func (s *SortedSet[T]) Union(other *SortedSet[T]) *SortedSet[T] {
+	result := s.Clone()
+	for x := other.head.next[0].node; x != nil; x = x.next[0].node {
+		result.insert(x.val)
+	}
+	return result
+}
+
+// Intersect 交集，遍历两者中较小的 set
+func (s *SortedSet[T]) Intersect(other *SortedSet[T]) *SortedSet[T] {
+	small, large := s, other
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+	result := NewSortedSetFunc(s.cmp)
+	for x := small.head.next[0].node; x != nil; x = x.next[0].node {
+		if large.Contains(x.val) {
+			result.insert(x.val)
+		}
+	}
+	return result
+}
+
+// Difference 差集
+func (s *SortedSet[T]) Difference(other *SortedSet[T]) *SortedSet[T] {
+	return s.Filter(func(v T) bool {
+		return !other.Contains(v)
+	})
+}
+
+// SymmetricDifference 对称差集，即只在其中一个 set 中出现的元素
+func (s *SortedSet[T]) SymmetricDifference(other *SortedSet[T]) *SortedSet[T] {
+	result := s.Difference(other)
+	for x := other.head.next[0].node; x != nil; x = x.next[0].node {
+		if !s.Contains(x.val) {
+			result.insert(x.val)
+		}
+	}
+	return result
+}
+
+// IsSubset s 是否为 other 的子集
+func (s *SortedSet[T]) IsSubset(other *SortedSet[T]) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+	for x := s.head.next[0].node; x != nil; x = x.next[0].node {
+		if !other.Contains(x.val) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset s 是否为 other 的超集
+func (s *SortedSet[T]) IsSuperset(other *SortedSet[T]) bool {
+	return other.IsSubset(s)
+}
+
+// IsDisjoint 两个 set 是否没有公共元素，遍历两者中较小的 set
+func (s *SortedSet[T]) IsDisjoint(other *SortedSet[T]) bool {
+	small, large := s, other
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+	for x := small.head.next[0].node; x != nil; x = x.next[0].node {
+		if large.Contains(x.val) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal 两个 set 是否包含相同的元素，两者有序，逐个比较即可
+func (s *SortedSet[T]) Equal(other *SortedSet[T]) bool {
+	if s.Len() != other.Len() {
+		return false
+	}
+	y := other.head.next[0].node
+	for x := s.head.next[0].node; x != nil; x = x.next[0].node {
+		if s.cmp(x.val, y.val) != 0 {
+			return false
+		}
+		y = y.next[0].node
+	}
+	return true
+}
+
+// Filter 返回满足 fn 的元素组成的新 set
+func (s *SortedSet[T]) Filter(fn func(T) bool) *SortedSet[T] {
+	result := NewSortedSetFunc(s.cmp)
+	for x := s.head.next[0].node; x != nil; x = x.next[0].node {
+		if fn(x.val) {
+			result.insert(x.val)
+		}
+	}
+	return result
+}
+
+// UnionAll s 与多个 set 的并集，结果使用 s 的排序
+func (s *SortedSet[T]) UnionAll(others ...*SortedSet[T]) *SortedSet[T] {
+	result := s.Clone()
+	for _, other := range others {
+		for x := other.head.next[0].node; x != nil; x = x.next[0].node {
+			result.insert(x.val)
+		}
+	}
+	return result
+}
+
+// IntersectAll s 与多个 set 的交集，遍历其中最小的 set，结果使用 s 的排序
+func (s *SortedSet[T]) IntersectAll(others ...*SortedSet[T]) *SortedSet[T] {
+	smallest := s
+	for _, other := range others {
+		if other.Len() < smallest.Len() {
+			smallest = other
+		}
+	}
+	result := NewSortedSetFunc(s.cmp)
+	for x := smallest.head.next[0].node; x != nil; x = x.next[0].node {
+		in := smallest == s || s.Contains(x.val)
+		for _, other := range others {
+			if !in {
+				break
+			}
+			in = other == smallest || other.Contains(x.val)
+		}
+		if in {
+			result.insert(x.val)
+		}
+	}
+	return result
+}
+
+// MapSorted 将 set 中的元素逐个转换后组成按自然顺序排列的新 set，转换结果相同的元素会合并
+func MapSorted[T any, U cmp.Ordered](s *SortedSet[T], fn func(T) U) *SortedSet[U] {
+	return MapSortedFunc(s, fn, cmp.Compare[U])
+}
+
+// MapSortedFunc 与 MapSorted 相同，新 set 按 cmp 排列
+func MapSortedFunc[T, U any](s *SortedSet[T], fn func(T) U, cmp func(a, b U) int) *SortedSet[U] {
+	result := NewSortedSetFunc(cmp)
+	for x := s.head.next[0].node; x != nil; x = x.next[0].node {
+		result.insert(fn(x.val))
+	}
+	return result
+}
+
+// insert 插入元素，已存在时返回 false
+func (s *SortedSet[T]) insert(elem T) bool {
+	var (
+		update [skipMaxLevel]*skipNode[T]
+		rank   [skipMaxLevel]int
+	)
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		if i < s.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for next := x.next[i].node; next != nil && s.cmp(next.val, elem) < 0; next = x.next[i].node {
+			rank[i] += x.next[i].span
+			x = next
+		}
+		update[i] = x
+	}
+	if next := x.next[0].node; next != nil && s.cmp(next.val, elem) == 0 {
+		return false
+	}
+
+	level := randomLevel()
+	if level > s.level {
+		for i := s.level; i < level; i++ {
+			rank[i] = 0
+			update[i] = s.head
+			update[i].next[i].span = s.length
+		}
+		s.level = level
+	}
+
+	n := &skipNode[T]{val: elem, next: make([]skipLink[T], level)}
+	for i := 0; i < level; i++ {
+		n.next[i].node = update[i].next[i].node
+		n.next[i].span = update[i].next[i].span - (rank[0] - rank[i])
+		update[i].next[i].node = n
+		update[i].next[i].span = rank[0] - rank[i] + 1
+	}
+	// 新节点没有达到的层，跨过新节点的链接长度加一
+	for i := level; i < s.level; i++ {
+		update[i].next[i].span++
+	}
+
+	if update[0] != s.head {
+		n.prev = update[0]
+	}
+	if next := n.next[0].node; next != nil {
+		next.prev = n
+	} else {
+		s.tail = n
+	}
+	s.length++
+	return true
+}
+
+// lower 返回最后一个小于 elem 的节点，不存在时返回 head
+func (s *SortedSet[T]) lower(elem T) *skipNode[T] {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for next := x.next[i].node; next != nil && s.cmp(next.val, elem) < 0; next = x.next[i].node {
+			x = next
+		}
+	}
+	return x
+}
+
+func randomLevel() int {
+	level := 1
+	for level < skipMaxLevel && rand.IntN(skipP) == 0 {
+		level++
+	}
+	return level
+}
+
+func nodeVal[T any](x *skipNode[T]) (T, bool) {
+	if x == nil {
+		var zero T
+		return zero, false
+	}
+	return x.val, true
+}
@@ -0,0 +1,198 @@
+package set
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSortedSetBasicOperations(t *testing.T) {
+	s := NewSortedSet(5, 1, 3, 3)
+
+	if s.Len() != 3 {
+		t.Errorf("expected length 3, got %d", s.Len())
+	}
+	if !reflect.DeepEqual(s.Items(), []int{1, 3, 5}) {
+		t.Errorf("expected sorted items, got %v", s.Items())
+	}
+	if !s.Contains(3) || s.Contains(4) {
+		t.Errorf("contains failed")
+	}
+	if s.AddIfAbsent(3) || !s.AddIfAbsent(4) {
+		t.Errorf("AddIfAbsent failed")
+	}
+
+	s.Delete(3)
+	s.Delete(100)
+	if !reflect.DeepEqual(s.Items(), []int{1, 4, 5}) {
+		t.Errorf("delete failed, got %v", s.Items())
+	}
+
+	s.Clear()
+	if s.Len() != 0 || len(s.Items()) != 0 {
+		t.Errorf("clear failed, len=%d", s.Len())
+	}
+	if _, ok := s.Min(); ok {
+		t.Errorf("expected Min on empty set to fail")
+	}
+}
+
+func TestSortedSetNavigation(t *testing.T) {
+	s := NewSortedSet(10, 20, 30, 40)
+
+	tests := []struct {
+		name   string
+		fn     func(int) (int, bool)
+		arg    int
+		want   int
+		wantOk bool
+	}{
+		{"floor exact", s.Floor, 20, 20, true},
+		{"floor between", s.Floor, 25, 20, true},
+		{"floor below min", s.Floor, 5, 0, false},
+		{"ceiling exact", s.Ceiling, 30, 30, true},
+		{"ceiling between", s.Ceiling, 25, 30, true},
+		{"ceiling above max", s.Ceiling, 45, 0, false},
+		{"at first", s.At, 0, 10, true},
+		{"at last", s.At, 3, 40, true},
+		{"at out of range", s.At, 4, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.fn(tt.arg)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("%s: expected (%v, %v), got (%v, %v)", tt.name, tt.want, tt.wantOk, got, ok)
+		}
+	}
+
+	if lo, _ := s.Min(); lo != 10 {
+		t.Errorf("expected min 10, got %d", lo)
+	}
+	if hi, _ := s.Max(); hi != 40 {
+		t.Errorf("expected max 40, got %d", hi)
+	}
+	if got := s.RangeBetween(15, 30); !reflect.DeepEqual(got, []int{20, 30}) {
+		t.Errorf("range between failed, got %v", got)
+	}
+	if got := s.RangeBetween(50, 60); len(got) != 0 {
+		t.Errorf("expected empty range, got %v", got)
+	}
+	if s.Rank(10) != 0 || s.Rank(30) != 2 || s.Rank(35) != 3 {
+		t.Errorf("rank failed: %d %d %d", s.Rank(10), s.Rank(30), s.Rank(35))
+	}
+	if got := slices.Collect(s.Backward()); !reflect.DeepEqual(got, []int{40, 30, 20, 10}) {
+		t.Errorf("backward failed, got %v", got)
+	}
+}
+
+func TestSortedSetFunc(t *testing.T) {
+	// 忽略大小写
+	s := NewSortedSetFunc(func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	s.Add("b", "A", "a", "C")
+
+	if !reflect.DeepEqual(s.Items(), []string{"A", "b", "C"}) {
+		t.Errorf("expected case-insensitive order, got %v", s.Items())
+	}
+}
+
+func TestSortedSetOperations(t *testing.T) {
+	a := NewSortedSet(1, 2, 3, 4)
+	b := NewSortedSet(3, 4, 5)
+
+	tests := []struct {
+		name string
+		got  *SortedSet[int]
+		want []int
+	}{
+		{"union", a.Union(b), []int{1, 2, 3, 4, 5}},
+		{"intersect", a.Intersect(b), []int{3, 4}},
+		{"difference", a.Difference(b), []int{1, 2}},
+		{"symmetric difference", a.SymmetricDifference(b), []int{1, 2, 5}},
+		{"filter", a.Filter(func(v int) bool { return v%2 == 1 }), []int{1, 3}},
+		{"clone", a.Clone(), []int{1, 2, 3, 4}},
+		{"union all", a.UnionAll(b, NewSortedSet(0, 9)), []int{0, 1, 2, 3, 4, 5, 9}},
+		{"union all none", a.UnionAll(), []int{1, 2, 3, 4}},
+		{"intersect all", a.IntersectAll(b, NewSortedSet(4, 5, 6)), []int{4}},
+		{"intersect all smallest first", NewSortedSet(4).IntersectAll(a, b), []int{4}},
+		{"intersect all none", a.IntersectAll(), []int{1, 2, 3, 4}},
+		{"map", MapSorted(a, func(v int) int { return -v / 2 }), []int{-2, -1, 0}},
+		{"map func", MapSortedFunc(a, func(v int) int { return v * 10 }, func(x, y int) int { return y - x }), []int{40, 30, 20, 10}},
+	}
+	for _, tt := range tests {
+		if got := tt.got.Items(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+
+	if !NewSortedSet(3, 4).IsSubset(a) || a.IsSubset(b) || !a.IsSuperset(NewSortedSet(1)) {
+		t.Errorf("subset failed")
+	}
+	if a.IsDisjoint(b) || !a.IsDisjoint(NewSortedSet(9)) {
+		t.Errorf("disjoint failed")
+	}
+	if !a.Equal(NewSortedSet(4, 3, 2, 1)) || a.Equal(b) {
+		t.Errorf("equal failed")
+	}
+
+	v, ok := a.Pop()
+	if !ok || v != 1 || a.Len() != 3 {
+		t.Errorf("pop failed, got %v %v len=%d", v, ok, a.Len())
+	}
+}
+
+// 与排序切片对照随机操作的结果
+func TestSortedSetRandomized(t *testing.T) {
+	s := NewSortedSet[int]()
+	var ref []int
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 5000; i++ {
+		v := r.IntN(500)
+		idx, found := slices.BinarySearch(ref, v)
+		if r.IntN(3) == 0 {
+			s.Delete(v)
+			if found {
+				ref = slices.Delete(ref, idx, idx+1)
+			}
+		} else {
+			s.Add(v)
+			if !found {
+				ref = slices.Insert(ref, idx, v)
+			}
+		}
+
+		if s.Len() != len(ref) {
+			t.Fatalf("step %d: expected len %d, got %d", i, len(ref), s.Len())
+		}
+		q := r.IntN(520) - 10
+		want, _ := slices.BinarySearch(ref, q)
+		if got := s.Rank(q); got != want {
+			t.Fatalf("step %d: Rank(%d) expected %d, got %d", i, q, want, got)
+		}
+		if len(ref) > 0 {
+			k := r.IntN(len(ref))
+			if got, _ := s.At(k); got != ref[k] {
+				t.Fatalf("step %d: At(%d) expected %d, got %d", i, k, ref[k], got)
+			}
+		}
+	}
+	if !slices.Equal(s.Items(), ref) {
+		t.Errorf("items mismatch")
+	}
+	back := slices.Collect(s.Backward())
+	slices.Reverse(back)
+	if !reflect.DeepEqual(back, s.Items()) {
+		t.Errorf("backward iteration mismatch")
+	}
+}
+
+func BenchmarkSortedSetAdd(b *testing.B) {
+	s := NewSortedSet[int]()
+	i := 0
+	for b.Loop() {
+		s.Add(i * 7919 % 1000003)
+		i++
+	}
+}
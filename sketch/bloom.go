@@ -0,0 +1,156 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+)
+
+const (
+	bloomMagic         = 'B'
+	countingBloomMagic = 'C'
+	binaryVersion      = 1
+	// bloomHeaderSize magic、version、m、k
+	bloomHeaderSize = 2 + 8 + 4
+)
+
+// BloomFilter 布隆过滤器，Contains 返回 false 时 key 一定不存在，返回 true 时 key 可能存在
+type BloomFilter[K comparable] struct {
+	bits []uint64
+	// m 为位数，k 为每个 key 对应的位数
+	m    uint64
+	k    uint32
+	hash func(K) uint64
+}
+
+// NewBloomFilter 按预计元素个数 n 与期望的误判率 fpRate 计算位数与哈希函数个数
+func NewBloomFilter[K comparable](n uint64, fpRate float64, opts ...Option) *BloomFilter[K] {
+	m, k := optimalBloom(n, fpRate)
+	return NewBloomFilterWithSize[K](m, k, opts...)
+}
+
+// NewBloomFilterWithSize 直接指定位数 m 与哈希函数个数 k
+func NewBloomFilterWithSize[K comparable](m uint64, k uint32, opts ...Option) *BloomFilter[K] {
+	m, k = max(m, 1), max(k, 1)
+	return &BloomFilter[K]{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+		hash: hasherFunc[K](opts),
+	}
+}
+
+// optimalBloom m = -n*ln(p)/ln(2)^2，k = m/n*ln(2)
+func optimalBloom(n uint64, fpRate float64) (uint64, uint32) {
+	n = max(n, 1)
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+	m := math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(n) * math.Ln2)
+	return uint64(m), uint32(max(k, 1))
+}
+
+// Add 添加 key
+func (b *BloomFilter[K]) Add(key K) {
+	h1, h2 := doubleHash(b.hash(key))
+	for i := uint64(0); i < uint64(b.k); i++ {
+		pos := (h1 + i*h2) % b.m
+		b.bits[pos/64] |= 1 << (pos % 64)
+	}
+}
+
+// Contains key 是否可能存在
+func (b *BloomFilter[K]) Contains(key K) bool {
+	h1, h2 := doubleHash(b.hash(key))
+	for i := uint64(0); i < uint64(b.k); i++ {
+		pos := (h1 + i*h2) % b.m
+		if b.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Cap 返回位数
+func (b *BloomFilter[K]) Cap() uint64 {
+	return b.m
+}
+
+// K 返回每个 key 对应的位数
+func (b *BloomFilter[K]) K() uint32 {
+	return b.k
+}
+
+// EstimatedFalsePositiveRate 根据当前置位的比例估算误判率
+func (b *BloomFilter[K]) EstimatedFalsePositiveRate() float64 {
+	set := 0
+	for _, w := range b.bits {
+		set += bits.OnesCount64(w)
+	}
+	return math.Pow(float64(set)/float64(b.m), float64(b.k))
+}
+
+// Clear 清空
+func (b *BloomFilter[K]) Clear() {
+	clear(b.bits)
+}
+
+// Merge 合并另一个参数相同的布隆过滤器，合并后包含两者的所有 key
+func (b *BloomFilter[K]) Merge(other *BloomFilter[K]) error {
+	if b.m != other.m || b.k != other.k {
+		return ErrIncompatible
+	}
+	for i, w := range other.bits {
+		b.bits[i] |= w
+	}
+	return nil
+}
+
+// MarshalBinary 编码为二进制，哈希函数不会被编码
+func (b *BloomFilter[K]) MarshalBinary() ([]byte, error) {
+	data := appendHeader(make([]byte, 0, bloomHeaderSize+8*len(b.bits)), bloomMagic, b.m, b.k)
+	for _, w := range b.bits {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary 解码 MarshalBinary 的输出，可以解码到零值的 BloomFilter 中（使用默认哈希函数）
+func (b *BloomFilter[K]) UnmarshalBinary(data []byte) error {
+	m, k, body, err := readHeader(data, bloomMagic)
+	if err != nil {
+		return err
+	}
+	// m 可能接近 uint64 的上限，用 (m-1)/64+1 计算字数避免溢出
+	if len(body)%8 != 0 || uint64(len(body)/8) != (m-1)/64+1 {
+		return errInvalidData
+	}
+	words := make([]uint64, len(body)/8)
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(body[i*8:])
+	}
+	b.bits, b.m, b.k = words, m, k
+	if b.hash == nil {
+		b.hash = hasherFunc[K](nil)
+	}
+	return nil
+}
+
+func appendHeader(data []byte, magic byte, m uint64, k uint32) []byte {
+	data = append(data, magic, binaryVersion)
+	data = binary.LittleEndian.AppendUint64(data, m)
+	return binary.LittleEndian.AppendUint32(data, k)
+}
+
+func readHeader(data []byte, magic byte) (m uint64, k uint32, body []byte, err error) {
+	if len(data) < bloomHeaderSize || data[0] != magic || data[1] != binaryVersion {
+		return 0, 0, nil, errInvalidData
+	}
+	m = binary.LittleEndian.Uint64(data[2:])
+	k = binary.LittleEndian.Uint32(data[10:])
+	if m == 0 || k == 0 {
+		return 0, 0, nil, errInvalidData
+	}
+	return m, k, data[bloomHeaderSize:], nil
+}
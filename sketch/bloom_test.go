@@ -0,0 +1,212 @@
+package sketch
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/Ri0nGo/gokit/internal/hashing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloomFilter(t *testing.T) {
+	testCase := []struct {
+		name   string
+		n      uint64
+		fpRate float64
+	}{
+		{name: "1%", n: 10000, fpRate: 0.01},
+		{name: "0.1%", n: 10000, fpRate: 0.001},
+		{name: "invalid rate uses default", n: 1000, fpRate: 2},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewBloomFilter[string](tc.n, tc.fpRate)
+			for i := uint64(0); i < tc.n; i++ {
+				b.Add("key-" + strconv.FormatUint(i, 10))
+			}
+			// 没有漏判
+			for i := uint64(0); i < tc.n; i++ {
+				assert.True(t, b.Contains("key-"+strconv.FormatUint(i, 10)))
+			}
+
+			rate := tc.fpRate
+			if rate >= 1 {
+				rate = 0.01
+			}
+			fp := 0
+			const probes = 100000
+			for i := 0; i < probes; i++ {
+				if b.Contains("other-" + strconv.Itoa(i)) {
+					fp++
+				}
+			}
+			// 实际误判率不超过目标的两倍
+			assert.Less(t, float64(fp)/probes, rate*2)
+			assert.InDelta(t, rate, b.EstimatedFalsePositiveRate(), rate)
+		})
+	}
+}
+
+func TestBloomFilterMerge(t *testing.T) {
+	a := NewBloomFilter[int](1000, 0.01)
+	b := NewBloomFilter[int](1000, 0.01)
+	for i := 0; i < 500; i++ {
+		a.Add(i)
+		b.Add(i + 500)
+	}
+	assert.NoError(t, a.Merge(b))
+	for i := 0; i < 1000; i++ {
+		assert.True(t, a.Contains(i))
+	}
+
+	assert.ErrorIs(t, a.Merge(NewBloomFilter[int](2000, 0.01)), ErrIncompatible)
+
+	a.Clear()
+	assert.False(t, a.Contains(1))
+}
+
+func TestBloomFilterBinary(t *testing.T) {
+	b := NewBloomFilter[string](1000, 0.01)
+	b.Add("a")
+	b.Add("b")
+	data, err := b.MarshalBinary()
+	assert.NoError(t, err)
+
+	var got BloomFilter[string]
+	assert.NoError(t, got.UnmarshalBinary(data))
+	assert.Equal(t, b.Cap(), got.Cap())
+	assert.Equal(t, b.K(), got.K())
+	assert.True(t, got.Contains("a"))
+	assert.True(t, got.Contains("b"))
+
+	assert.Error(t, got.UnmarshalBinary(data[:len(data)-1]))
+	assert.Error(t, got.UnmarshalBinary([]byte{'C', 1}))
+	var counting CountingBloomFilter[string]
+	assert.Error(t, counting.UnmarshalBinary(data))
+}
+
+func TestBloomFilterUnmarshalInvalid(t *testing.T) {
+	bloom := (&BloomFilter[string]{}).UnmarshalBinary
+	counting := (&CountingBloomFilter[string]{}).UnmarshalBinary
+	testCase := []struct {
+		name   string
+		decode func([]byte) error
+		magic  byte
+		m      uint64
+		body   []byte
+	}{
+		// 按位数计算的长度在 uint64 上溢出后为 0，不能被空的 body 通过校验
+		{name: "bloom huge m", decode: bloom, magic: bloomMagic, m: math.MaxUint64},
+		{name: "bloom huge m with body", decode: bloom, magic: bloomMagic, m: math.MaxUint64 - 62, body: make([]byte, 8)},
+		{name: "bloom body not aligned", decode: bloom, magic: bloomMagic, m: 64, body: make([]byte, 9)},
+		{name: "bloom body too short", decode: bloom, magic: bloomMagic, m: 65, body: make([]byte, 8)},
+		{name: "counting huge m", decode: counting, magic: countingBloomMagic, m: math.MaxUint64},
+		{name: "counting body too short", decode: counting, magic: countingBloomMagic, m: 3, body: make([]byte, 1)},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			data := append(appendHeader(nil, tc.magic, tc.m, 3), tc.body...)
+			assert.ErrorIs(t, tc.decode(data), errInvalidData)
+		})
+	}
+}
+
+func TestBloomFilterWithHasher(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	b := NewBloomFilter[user](100, 0.01, WithHasher(func(u user) uint64 {
+		return uint64(u.ID)
+	}))
+	b.Add(user{ID: 1, Name: "a"})
+	// 自定义哈希只看 ID
+	assert.True(t, b.Contains(user{ID: 1, Name: "b"}))
+
+	assert.Panics(t, func() {
+		NewBloomFilter[string](100, 0.01, WithHasher(func(i int) uint64 { return 0 }))
+	})
+}
+
+func TestCountingBloomFilter(t *testing.T) {
+	b := NewCountingBloomFilter[int](1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		b.Add(i)
+	}
+	for i := 0; i < 500; i++ {
+		assert.True(t, b.Delete(i))
+	}
+	// 删除后剩余的 key 没有漏判
+	for i := 500; i < 1000; i++ {
+		assert.True(t, b.Contains(i))
+	}
+	fp := 0
+	for i := 0; i < 500; i++ {
+		if b.Contains(i) {
+			fp++
+		}
+	}
+	assert.Less(t, fp, 25)
+
+	// 一定不存在的 key 不会被删除
+	assert.False(t, NewCountingBloomFilter[int](1000, 0.01).Delete(1))
+}
+
+func TestCountingBloomFilterSaturate(t *testing.T) {
+	b := NewCountingBloomFilter[string](10, 0.01)
+	for i := 0; i < 100; i++ {
+		b.Add("hot")
+	}
+	for i := 0; i < 100; i++ {
+		b.Delete("hot")
+	}
+	// 计数器达到上限后不再减少，不会产生漏判
+	assert.True(t, b.Contains("hot"))
+}
+
+func TestCountingBloomFilterMergeAndBinary(t *testing.T) {
+	a := NewCountingBloomFilter[string](100, 0.01)
+	b := NewCountingBloomFilter[string](100, 0.01)
+	a.Add("a")
+	b.Add("a")
+	b.Add("b")
+	assert.NoError(t, a.Merge(b))
+	assert.True(t, a.Contains("b"))
+
+	// "a" 被计数两次，删除一次后仍然存在
+	a.Delete("a")
+	assert.True(t, a.Contains("a"))
+
+	data, err := a.MarshalBinary()
+	assert.NoError(t, err)
+	var got CountingBloomFilter[string]
+	assert.NoError(t, got.UnmarshalBinary(data))
+	assert.True(t, got.Contains("a"))
+	assert.True(t, got.Contains("b"))
+	assert.True(t, got.ToBloomFilter().Contains("b"))
+
+	assert.ErrorIs(t, a.Merge(NewCountingBloomFilter[string](1000, 0.01)), ErrIncompatible)
+}
+
+func BenchmarkBloomFilterContains(b *testing.B) {
+	bf := NewBloomFilter[string](100000, 0.01)
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = "key-" + strconv.Itoa(i)
+		bf.Add(keys[i])
+	}
+	i := 0
+	for b.Loop() {
+		bf.Contains(keys[i&1023])
+		i++
+	}
+}
+
+func TestDefaultHasherStable(t *testing.T) {
+	// 默认哈希函数不带随机种子，序列化后的结构可以在其他进程中继续使用
+	assert.Equal(t, uint64(0x44bd8ad473cd9906), hasherFunc[string](nil)("a"))
+	assert.Equal(t, hashing.Mix64(42), hasherFunc[int](nil)(42))
+}
@@ -0,0 +1,138 @@
+package sketch
+
+// counterMax 每个计数器占 4 位，达到上限后不再增减，避免删除其他 key 时产生漏判
+const counterMax = 15
+
+// CountingBloomFilter 计数布隆过滤器，每个位置使用 4 位计数器代替单个位，因此支持 Delete，
+// 内存占用是同参数 BloomFilter 的 4 倍
+type CountingBloomFilter[K comparable] struct {
+	// counters 每个字节保存两个计数器，低 4 位为偶数位置
+	counters []byte
+	m        uint64
+	k        uint32
+	hash     func(K) uint64
+}
+
+// NewCountingBloomFilter 按预计元素个数 n 与期望的误判率 fpRate 计算计数器个数与哈希函数个数
+func NewCountingBloomFilter[K comparable](n uint64, fpRate float64, opts ...Option) *CountingBloomFilter[K] {
+	m, k := optimalBloom(n, fpRate)
+	m, k = max(m, 1), max(k, 1)
+	return &CountingBloomFilter[K]{
+		counters: make([]byte, (m+1)/2),
+		m:        m,
+		k:        k,
+		hash:     hasherFunc[K](opts),
+	}
+}
+
+// Add 添加 key
+func (b *CountingBloomFilter[K]) Add(key K) {
+	h1, h2 := doubleHash(b.hash(key))
+	for i := uint64(0); i < uint64(b.k); i++ {
+		pos := (h1 + i*h2) % b.m
+		if c := b.get(pos); c < counterMax {
+			b.set(pos, c+1)
+		}
+	}
+}
+
+// Delete 删除 key，key 不可能存在时不做任何事并返回 false。
+// 只应删除确实添加过的 key，否则可能使其他 key 被漏判
+func (b *CountingBloomFilter[K]) Delete(key K) bool {
+	if !b.Contains(key) {
+		return false
+	}
+	h1, h2 := doubleHash(b.hash(key))
+	for i := uint64(0); i < uint64(b.k); i++ {
+		pos := (h1 + i*h2) % b.m
+		if c := b.get(pos); c < counterMax {
+			b.set(pos, c-1)
+		}
+	}
+	return true
+}
+
+// Contains key 是否可能存在
+func (b *CountingBloomFilter[K]) Contains(key K) bool {
+	h1, h2 := doubleHash(b.hash(key))
+	for i := uint64(0); i < uint64(b.k); i++ {
+		if b.get((h1+i*h2)%b.m) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Cap 返回计数器个数
+func (b *CountingBloomFilter[K]) Cap() uint64 {
+	return b.m
+}
+
+// K 返回每个 key 对应的计数器个数
+func (b *CountingBloomFilter[K]) K() uint32 {
+	return b.k
+}
+
+// Clear 清空
+func (b *CountingBloomFilter[K]) Clear() {
+	clear(b.counters)
+}
+
+// Merge 合并另一个参数相同的计数布隆过滤器，对应的计数器相加（达到上限后不再增加）
+func (b *CountingBloomFilter[K]) Merge(other *CountingBloomFilter[K]) error {
+	if b.m != other.m || b.k != other.k {
+		return ErrIncompatible
+	}
+	for pos := uint64(0); pos < b.m; pos++ {
+		b.set(pos, min(b.get(pos)+other.get(pos), counterMax))
+	}
+	return nil
+}
+
+// ToBloomFilter 转换为使用相同哈希函数的 BloomFilter，计数器非 0 的位置被置位
+func (b *CountingBloomFilter[K]) ToBloomFilter() *BloomFilter[K] {
+	bf := &BloomFilter[K]{
+		bits: make([]uint64, (b.m+63)/64),
+		m:    b.m,
+		k:    b.k,
+		hash: b.hash,
+	}
+	for pos := uint64(0); pos < b.m; pos++ {
+		if b.get(pos) > 0 {
+			bf.bits[pos/64] |= 1 << (pos % 64)
+		}
+	}
+	return bf
+}
+
+// MarshalBinary 编码为二进制，哈希函数不会被编码
+func (b *CountingBloomFilter[K]) MarshalBinary() ([]byte, error) {
+	data := appendHeader(make([]byte, 0, bloomHeaderSize+len(b.counters)), countingBloomMagic, b.m, b.k)
+	return append(data, b.counters...), nil
+}
+
+// UnmarshalBinary 解码 MarshalBinary 的输出，可以解码到零值的 CountingBloomFilter 中（使用默认哈希函数）
+func (b *CountingBloomFilter[K]) UnmarshalBinary(data []byte) error {
+	m, k, body, err := readHeader(data, countingBloomMagic)
+	if err != nil {
+		return err
+	}
+	// 与 BloomFilter 一样避免 m+1 溢出
+	if uint64(len(body)) != (m-1)/2+1 {
+		return errInvalidData
+	}
+	b.counters, b.m, b.k = append([]byte(nil), body...), m, k
+	if b.hash == nil {
+		b.hash = hasherFunc[K](nil)
+	}
+	return nil
+}
+
+func (b *CountingBloomFilter[K]) get(pos uint64) byte {
+	return b.counters[pos/2] >> (4 * (pos % 2)) & 0x0f
+}
+
+func (b *CountingBloomFilter[K]) set(pos uint64, c byte) {
+	shift := 4 * (pos % 2)
+	b.counters[pos/2] = b.counters[pos/2]&^(0x0f<<shift) | c<<shift
+}
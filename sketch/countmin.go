@@ -0,0 +1,244 @@
+package sketch
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"math"
+	"slices"
+)
+
+const cmsMagic = 'M'
+
+// CountMinSketch 频率估计，估计值不会小于真实值，
+// 以 1-delta 的概率满足 估计值 <= 真实值 + epsilon*Total()
+type CountMinSketch[K comparable] struct {
+	width, depth uint32
+	// counters 按行存放，共 depth 行，每行 width 个计数器
+	counters []uint64
+	total    uint64
+	hash     func(K) uint64
+}
+
+// NewCountMinSketch 按误差 epsilon 与失败概率 delta 计算宽度 e/epsilon 与深度 ln(1/delta)
+func NewCountMinSketch[K comparable](epsilon, delta float64, opts ...Option) *CountMinSketch[K] {
+	if epsilon <= 0 || epsilon >= 1 {
+		epsilon = 0.001
+	}
+	if delta <= 0 || delta >= 1 {
+		delta = 0.01
+	}
+	width := uint32(math.Ceil(math.E / epsilon))
+	depth := uint32(math.Ceil(math.Log(1 / delta)))
+	return NewCountMinSketchWithSize[K](width, depth, opts...)
+}
+
+// NewCountMinSketchWithSize 直接指定宽度与深度
+func NewCountMinSketchWithSize[K comparable](width, depth uint32, opts ...Option) *CountMinSketch[K] {
+	width, depth = max(width, 1), max(depth, 1)
+	return &CountMinSketch[K]{
+		width:    width,
+		depth:    depth,
+		counters: make([]uint64, uint64(width)*uint64(depth)),
+		hash:     hasherFunc[K](opts),
+	}
+}
+
+// Add 将 key 的计数增加 n，返回增加后的估计值
+func (c *CountMinSketch[K]) Add(key K, n uint64) uint64 {
+	h1, h2 := doubleHash(c.hash(key))
+	est := uint64(math.MaxUint64)
+	for i := uint64(0); i < uint64(c.depth); i++ {
+		idx := i*uint64(c.width) + (h1+i*h2)%uint64(c.width)
+		c.counters[idx] += n
+		est = min(est, c.counters[idx])
+	}
+	c.total += n
+	return est
+}
+
+// Count 返回 key 的估计计数
+func (c *CountMinSketch[K]) Count(key K) uint64 {
+	h1, h2 := doubleHash(c.hash(key))
+	est := uint64(math.MaxUint64)
+	for i := uint64(0); i < uint64(c.depth); i++ {
+		est = min(est, c.counters[i*uint64(c.width)+(h1+i*h2)%uint64(c.width)])
+	}
+	return est
+}
+
+// Total 返回所有 key 的计数之和
+func (c *CountMinSketch[K]) Total() uint64 {
+	return c.total
+}
+
+// Clear 清空
+func (c *CountMinSketch[K]) Clear() {
+	clear(c.counters)
+	c.total = 0
+}
+
+// Merge 合并另一个大小相同的 CountMinSketch，对应的计数器相加
+func (c *CountMinSketch[K]) Merge(other *CountMinSketch[K]) error {
+	if c.width != other.width || c.depth != other.depth {
+		return ErrIncompatible
+	}
+	for i, v := range other.counters {
+		c.counters[i] += v
+	}
+	c.total += other.total
+	return nil
+}
+
+// MarshalBinary 编码为二进制：magic、version、width、depth、total 以及所有计数器
+func (c *CountMinSketch[K]) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, 2+4+4+8+8*len(c.counters))
+	data = append(data, cmsMagic, binaryVersion)
+	data = binary.LittleEndian.AppendUint32(data, c.width)
+	data = binary.LittleEndian.AppendUint32(data, c.depth)
+	data = binary.LittleEndian.AppendUint64(data, c.total)
+	for _, v := range c.counters {
+		data = binary.LittleEndian.AppendUint64(data, v)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary 解码 MarshalBinary 的输出，可以解码到零值的 CountMinSketch 中（使用默认哈希函数）
+func (c *CountMinSketch[K]) UnmarshalBinary(data []byte) error {
+	if len(data) < 18 || data[0] != cmsMagic || data[1] != binaryVersion {
+		return errInvalidData
+	}
+	width := binary.LittleEndian.Uint32(data[2:])
+	depth := binary.LittleEndian.Uint32(data[6:])
+	body := data[18:]
+	// width*depth 不会溢出 uint64，但再乘以 8 可能溢出，因此比较字数
+	if width == 0 || depth == 0 || len(body)%8 != 0 || uint64(len(body)/8) != uint64(width)*uint64(depth) {
+		return errInvalidData
+	}
+	counters := make([]uint64, len(body)/8)
+	for i := range counters {
+		counters[i] = binary.LittleEndian.Uint64(body[i*8:])
+	}
+	c.width, c.depth, c.counters = width, depth, counters
+	c.total = binary.LittleEndian.Uint64(data[10:])
+	if c.hash == nil {
+		c.hash = hasherFunc[K](nil)
+	}
+	return nil
+}
+
+// ItemCount key 及其估计计数
+type ItemCount[K comparable] struct {
+	Key   K
+	Count uint64
+}
+
+// TopK 基于 CountMinSketch 跟踪估计计数最大的 k 个 key
+type TopK[K comparable] struct {
+	k      int
+	sketch *CountMinSketch[K]
+	heap   topKHeap[K]
+	// index 记录 key 在堆中的位置
+	index map[K]int
+}
+
+// NewTopK 创建 TopK，epsilon 与 delta 的含义同 NewCountMinSketch
+func NewTopK[K comparable](k int, epsilon, delta float64, opts ...Option) *TopK[K] {
+	k = max(k, 1)
+	t := &TopK[K]{
+		k:      k,
+		sketch: NewCountMinSketch[K](epsilon, delta, opts...),
+		index:  make(map[K]int, k),
+	}
+	t.heap.index = t.index
+	return t
+}
+
+// Add 将 key 的计数增加 n
+func (t *TopK[K]) Add(key K, n uint64) {
+	est := t.sketch.Add(key, n)
+	if i, ok := t.index[key]; ok {
+		t.heap.items[i].Count = est
+		heap.Fix(&t.heap, i)
+		return
+	}
+	if len(t.heap.items) < t.k {
+		heap.Push(&t.heap, ItemCount[K]{Key: key, Count: est})
+		return
+	}
+	// 替换堆中计数最小的 key
+	if est > t.heap.items[0].Count {
+		delete(t.index, t.heap.items[0].Key)
+		t.heap.items[0] = ItemCount[K]{Key: key, Count: est}
+		t.index[key] = 0
+		heap.Fix(&t.heap, 0)
+	}
+}
+
+// Count 返回 key 的估计计数
+func (t *TopK[K]) Count(key K) uint64 {
+	return t.sketch.Count(key)
+}
+
+// Total 返回所有 key 的计数之和
+func (t *TopK[K]) Total() uint64 {
+	return t.sketch.Total()
+}
+
+// Top 按估计计数从大到小返回跟踪的 key
+func (t *TopK[K]) Top() []ItemCount[K] {
+	items := slices.Clone(t.heap.items)
+	slices.SortFunc(items, func(a, b ItemCount[K]) int {
+		switch {
+		case a.Count > b.Count:
+			return -1
+		case a.Count < b.Count:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return items
+}
+
+// HeavyHitters 返回估计计数不小于 fraction*Total() 的 key，按计数从大到小排列。
+// 只在跟踪的 k 个 key 中查找，fraction 应不小于 1/k
+func (t *TopK[K]) HeavyHitters(fraction float64) []ItemCount[K] {
+	threshold := fraction * float64(t.sketch.Total())
+	var res []ItemCount[K]
+	for _, item := range t.Top() {
+		if float64(item.Count) < threshold {
+			break
+		}
+		res = append(res, item)
+	}
+	return res
+}
+
+// topKHeap 按计数排列的小顶堆
+type topKHeap[K comparable] struct {
+	items []ItemCount[K]
+	index map[K]int
+}
+
+func (h *topKHeap[K]) Len() int { return len(h.items) }
+
+func (h *topKHeap[K]) Less(i, j int) bool { return h.items[i].Count < h.items[j].Count }
+
+func (h *topKHeap[K]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i].Key] = i
+	h.index[h.items[j].Key] = j
+}
+
+func (h *topKHeap[K]) Push(x any) {
+	item := x.(ItemCount[K])
+	h.index[item.Key] = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *topKHeap[K]) Pop() any {
+	item := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	delete(h.index, item.Key)
+	return item
+}
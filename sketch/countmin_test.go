@@ -0,0 +1,149 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	testCase := []struct {
+		name           string
+		epsilon, delta float64
+	}{
+		{name: "1%", epsilon: 0.01, delta: 0.01},
+		{name: "0.1%", epsilon: 0.001, delta: 0.001},
+		{name: "invalid uses default", epsilon: 0, delta: 2},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewCountMinSketch[string](tc.epsilon, tc.delta)
+			want := make(map[string]uint64)
+			for i := 0; i < 2000; i++ {
+				key := "key-" + strconv.Itoa(i%500)
+				n := uint64(i%7 + 1)
+				c.Add(key, n)
+				want[key] += n
+			}
+			var total uint64
+			for _, n := range want {
+				total += n
+			}
+			assert.Equal(t, total, c.Total())
+
+			eps := tc.epsilon
+			if eps <= 0 || eps >= 1 {
+				eps = 0.001
+			}
+			bad := 0
+			for key, n := range want {
+				got := c.Count(key)
+				// 估计值不会偏小
+				assert.GreaterOrEqual(t, got, n)
+				if float64(got) > float64(n)+eps*float64(total) {
+					bad++
+				}
+			}
+			assert.LessOrEqual(t, bad, len(want)/20)
+		})
+	}
+}
+
+func TestCountMinSketchMerge(t *testing.T) {
+	a := NewCountMinSketch[int](0.01, 0.01)
+	b := NewCountMinSketch[int](0.01, 0.01)
+	a.Add(1, 3)
+	b.Add(1, 4)
+	b.Add(2, 5)
+	assert.NoError(t, a.Merge(b))
+	assert.GreaterOrEqual(t, a.Count(1), uint64(7))
+	assert.GreaterOrEqual(t, a.Count(2), uint64(5))
+	assert.Equal(t, uint64(12), a.Total())
+
+	assert.ErrorIs(t, a.Merge(NewCountMinSketch[int](0.1, 0.01)), ErrIncompatible)
+
+	a.Clear()
+	assert.Equal(t, uint64(0), a.Count(1))
+	assert.Equal(t, uint64(0), a.Total())
+}
+
+func TestCountMinSketchBinary(t *testing.T) {
+	c := NewCountMinSketch[string](0.01, 0.01)
+	for i := 0; i < 1000; i++ {
+		c.Add(strconv.Itoa(i%100), 1)
+	}
+	data, err := c.MarshalBinary()
+	assert.NoError(t, err)
+
+	var got CountMinSketch[string]
+	assert.NoError(t, got.UnmarshalBinary(data))
+	assert.Equal(t, c.Total(), got.Total())
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, c.Count(strconv.Itoa(i)), got.Count(strconv.Itoa(i)))
+	}
+
+	assert.Error(t, got.UnmarshalBinary(nil))
+	assert.Error(t, got.UnmarshalBinary(data[:len(data)-1]))
+	assert.Error(t, got.UnmarshalBinary(append(data, 0)))
+}
+
+func TestCountMinSketchUnmarshalInvalid(t *testing.T) {
+	header := func(width, depth uint32) []byte {
+		data := []byte{cmsMagic, binaryVersion}
+		data = binary.LittleEndian.AppendUint32(data, width)
+		data = binary.LittleEndian.AppendUint32(data, depth)
+		return binary.LittleEndian.AppendUint64(data, 0)
+	}
+	testCase := []struct {
+		name string
+		data []byte
+	}{
+		// width*depth*8 = 2^65，在 uint64 上溢出后为 0
+		{name: "huge size", data: header(1<<31, 1<<31)},
+		{name: "huge size with body", data: append(header(1<<31, 1<<31), make([]byte, 8)...)},
+		{name: "zero width", data: header(0, 1)},
+		{name: "body not aligned", data: append(header(1, 1), make([]byte, 9)...)},
+		{name: "body too short", data: append(header(2, 2), make([]byte, 24)...)},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			var c CountMinSketch[string]
+			assert.ErrorIs(t, c.UnmarshalBinary(tc.data), errInvalidData)
+		})
+	}
+}
+
+func TestTopK(t *testing.T) {
+	tk := NewTopK[string](3, 0.001, 0.01)
+	// key-i 出现 (i+1)*10 次
+	for i := 0; i < 20; i++ {
+		for j := 0; j <= i; j++ {
+			tk.Add("key-"+strconv.Itoa(i), 10)
+		}
+	}
+
+	top := tk.Top()
+	assert.Equal(t, 3, len(top))
+	assert.Equal(t, "key-19", top[0].Key)
+	assert.Equal(t, "key-18", top[1].Key)
+	assert.Equal(t, "key-17", top[2].Key)
+	assert.GreaterOrEqual(t, top[0].Count, uint64(200))
+	assert.Equal(t, uint64(2100), tk.Total())
+
+	// 200/2100 ≈ 9.5%，190/2100 ≈ 9.0%
+	hh := tk.HeavyHitters(0.093)
+	assert.Equal(t, 1, len(hh))
+	assert.Equal(t, "key-19", hh[0].Key)
+	assert.Empty(t, tk.HeavyHitters(0.5))
+}
+
+func BenchmarkCountMinSketchAdd(b *testing.B) {
+	c := NewCountMinSketch[int](0.001, 0.01)
+	for i := 0; i < b.N; i++ {
+		c.Add(i, 1)
+	}
+}
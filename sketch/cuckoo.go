@@ -0,0 +1,267 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+	"math/rand/v2"
+
+	"github.com/Ri0nGo/gokit/internal/hashing"
+)
+
+const (
+	cuckooMagic = 'F'
+	// cuckooHeaderSize magic、version、bucketSize、fingerprintBits、numBuckets
+	cuckooHeaderSize = 2 + 1 + 1 + 8
+
+	defaultBucketSize      = 4
+	maxBucketSize          = 8
+	defaultFingerprintBits = 16
+	minFingerprintBits     = 4
+	maxFingerprintBits     = 32
+	// maxKicks 插入时最多搬迁的次数
+	maxKicks = 500
+)
+
+// ErrFilterFull 搬迁次数达到上限仍未找到空位，过滤器中的数据不受影响
+var ErrFilterFull = errors.New("sketch: cuckoo filter is full")
+
+// CuckooFilter 布谷鸟过滤器，与布隆过滤器一样 Lookup 返回 false 时 key 一定不存在，
+// 但支持删除，并且误判率较低时比布隆过滤器更省空间。误判率约为 2*bucketSize/2^fingerprintBits。
+// 同一个 key 可以插入多次（最多 2*bucketSize 次），需要删除同样的次数
+type CuckooFilter[K comparable] struct {
+	// slots 按位紧凑存放所有指纹，每个指纹 fpBits 位，0 表示空
+	slots      []uint64
+	numBuckets uint64
+	bucketSize uint8
+	fpBits     uint8
+	count      uint64
+	hash       func(K) uint64
+}
+
+// NewCuckooFilter 按预计元素个数 capacity 创建过滤器，
+// bucketSize 为每个桶的指纹个数 [1,8]，fingerprintBits 为指纹位数 [4,32]，传入 0 时分别使用默认值 4 与 16
+func NewCuckooFilter[K comparable](capacity uint64, bucketSize, fingerprintBits uint8, opts ...Option) *CuckooFilter[K] {
+	if bucketSize == 0 {
+		bucketSize = defaultBucketSize
+	}
+	bucketSize = min(bucketSize, maxBucketSize)
+	if fingerprintBits == 0 {
+		fingerprintBits = defaultFingerprintBits
+	}
+	fingerprintBits = min(max(fingerprintBits, minFingerprintBits), maxFingerprintBits)
+
+	// 按桶大小对应的最大负载率预留空间
+	loads := [...]float64{0.5, 0.84, 0.9, 0.95, 0.95, 0.96, 0.97, 0.98}
+	n := math.Ceil(float64(max(capacity, 1)) / float64(bucketSize) / loads[bucketSize-1])
+	numBuckets := uint64(1) << bits.Len64(uint64(n)-1)
+
+	return &CuckooFilter[K]{
+		slots:      make([]uint64, cuckooWords(numBuckets, bucketSize, fingerprintBits)),
+		numBuckets: numBuckets,
+		bucketSize: bucketSize,
+		fpBits:     fingerprintBits,
+		hash:       hasherFunc[K](opts),
+	}
+}
+
+func cuckooWords(numBuckets uint64, bucketSize, fpBits uint8) uint64 {
+	return (numBuckets*uint64(bucketSize)*uint64(fpBits) + 63) / 64
+}
+
+// Insert 插入 key，没有空位时返回 ErrFilterFull
+func (c *CuckooFilter[K]) Insert(key K) error {
+	fp, i1, i2 := c.locate(key)
+	if c.insertInto(i1, fp) || c.insertInto(i2, fp) {
+		c.count++
+		return nil
+	}
+
+	// 随机踢出一个指纹搬到它的另一个桶，记录路径以便失败时还原
+	path := make([]uint64, 0, 16)
+	i := i1
+	if rand.IntN(2) == 0 {
+		i = i2
+	}
+	cur := fp
+	for range maxKicks {
+		pos := i*uint64(c.bucketSize) + uint64(rand.IntN(int(c.bucketSize)))
+		victim := c.get(pos)
+		c.set(pos, cur)
+		path = append(path, pos)
+		cur = victim
+		i = c.altIndex(i, cur)
+		if c.insertInto(i, cur) {
+			c.count++
+			return nil
+		}
+	}
+	for j := len(path) - 1; j >= 0; j-- {
+		pos := path[j]
+		victim := c.get(pos)
+		c.set(pos, cur)
+		cur = victim
+	}
+	return ErrFilterFull
+}
+
+// Lookup key 是否可能存在
+func (c *CuckooFilter[K]) Lookup(key K) bool {
+	fp, i1, i2 := c.locate(key)
+	return c.find(i1, fp) >= 0 || c.find(i2, fp) >= 0
+}
+
+// Delete 删除 key 的一个指纹，不存在时返回 false。
+// 只能删除插入过的 key，否则可能误删指纹相同的其他 key
+func (c *CuckooFilter[K]) Delete(key K) bool {
+	fp, i1, i2 := c.locate(key)
+	for _, i := range [2]uint64{i1, i2} {
+		if pos := c.find(i, fp); pos >= 0 {
+			c.set(uint64(pos), 0)
+			c.count--
+			return true
+		}
+	}
+	return false
+}
+
+// Count 返回已插入的指纹个数
+func (c *CuckooFilter[K]) Count() uint64 {
+	return c.count
+}
+
+// Cap 返回指纹槽位的总数
+func (c *CuckooFilter[K]) Cap() uint64 {
+	return c.numBuckets * uint64(c.bucketSize)
+}
+
+// LoadFactor 返回已使用槽位的比例
+func (c *CuckooFilter[K]) LoadFactor() float64 {
+	return float64(c.count) / float64(c.Cap())
+}
+
+// BucketSize 返回每个桶的指纹个数
+func (c *CuckooFilter[K]) BucketSize() uint8 {
+	return c.bucketSize
+}
+
+// FingerprintBits 返回指纹位数
+func (c *CuckooFilter[K]) FingerprintBits() uint8 {
+	return c.fpBits
+}
+
+// Clear 清空
+func (c *CuckooFilter[K]) Clear() {
+	clear(c.slots)
+	c.count = 0
+}
+
+// MarshalBinary 编码为二进制：magic、version、bucketSize、fingerprintBits、numBuckets 以及紧凑存放的指纹
+func (c *CuckooFilter[K]) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, cuckooHeaderSize+8*len(c.slots))
+	data = append(data, cuckooMagic, binaryVersion, c.bucketSize, c.fpBits)
+	data = binary.LittleEndian.AppendUint64(data, c.numBuckets)
+	for _, w := range c.slots {
+		data = binary.LittleEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary 解码 MarshalBinary 的输出，可以解码到零值的 CuckooFilter 中（使用默认哈希函数）
+func (c *CuckooFilter[K]) UnmarshalBinary(data []byte) error {
+	if len(data) < cuckooHeaderSize || data[0] != cuckooMagic || data[1] != binaryVersion {
+		return errInvalidData
+	}
+	bucketSize, fpBits := data[2], data[3]
+	numBuckets := binary.LittleEndian.Uint64(data[4:])
+	if bucketSize == 0 || bucketSize > maxBucketSize ||
+		fpBits < minFingerprintBits || fpBits > maxFingerprintBits ||
+		numBuckets == 0 || numBuckets&(numBuckets-1) != 0 || numBuckets > 1<<48 {
+		return errInvalidData
+	}
+	body := data[cuckooHeaderSize:]
+	words := cuckooWords(numBuckets, bucketSize, fpBits)
+	if uint64(len(body)) != words*8 {
+		return errInvalidData
+	}
+
+	slots := make([]uint64, words)
+	for i := range slots {
+		slots[i] = binary.LittleEndian.Uint64(body[i*8:])
+	}
+	*c = CuckooFilter[K]{
+		slots:      slots,
+		numBuckets: numBuckets,
+		bucketSize: bucketSize,
+		fpBits:     fpBits,
+		hash:       c.hash,
+	}
+	for pos := range c.Cap() {
+		if c.get(pos) != 0 {
+			c.count++
+		}
+	}
+	if c.hash == nil {
+		c.hash = hasherFunc[K](nil)
+	}
+	return nil
+}
+
+// locate 计算 key 的指纹与两个候选桶，i2 = i1 ^ hash(fp)，因此可以只凭指纹在两个桶之间搬迁
+func (c *CuckooFilter[K]) locate(key K) (fp uint32, i1, i2 uint64) {
+	h := c.hash(key)
+	fp = uint32(hashing.Mix64(h) >> (64 - c.fpBits))
+	if fp == 0 {
+		fp = 1
+	}
+	i1 = h & (c.numBuckets - 1)
+	return fp, i1, c.altIndex(i1, fp)
+}
+
+func (c *CuckooFilter[K]) altIndex(i uint64, fp uint32) uint64 {
+	return (i ^ hashing.Mix64(uint64(fp))) & (c.numBuckets - 1)
+}
+
+// insertInto 将指纹放入桶 i 的空位
+func (c *CuckooFilter[K]) insertInto(i uint64, fp uint32) bool {
+	pos := c.find(i, 0)
+	if pos < 0 {
+		return false
+	}
+	c.set(uint64(pos), fp)
+	return true
+}
+
+// find 返回桶 i 中第一个等于 fp 的槽位，找不到时返回 -1
+func (c *CuckooFilter[K]) find(i uint64, fp uint32) int64 {
+	start := i * uint64(c.bucketSize)
+	for pos := start; pos < start+uint64(c.bucketSize); pos++ {
+		if c.get(pos) == fp {
+			return int64(pos)
+		}
+	}
+	return -1
+}
+
+// get 读取第 pos 个槽位，指纹可能跨越两个 uint64
+func (c *CuckooFilter[K]) get(pos uint64) uint32 {
+	off := pos * uint64(c.fpBits)
+	w, sh := off/64, off%64
+	v := c.slots[w] >> sh
+	if sh+uint64(c.fpBits) > 64 {
+		v |= c.slots[w+1] << (64 - sh)
+	}
+	return uint32(v & (1<<c.fpBits - 1))
+}
+
+// set 写入第 pos 个槽位
+func (c *CuckooFilter[K]) set(pos uint64, fp uint32) {
+	mask := uint64(1)<<c.fpBits - 1
+	off := pos * uint64(c.fpBits)
+	w, sh := off/64, off%64
+	c.slots[w] = c.slots[w]&^(mask<<sh) | uint64(fp)<<sh
+	if sh+uint64(c.fpBits) > 64 {
+		c.slots[w+1] = c.slots[w+1]&^(mask>>(64-sh)) | uint64(fp)>>(64-sh)
+	}
+}
@@ -0,0 +1,175 @@
+package sketch
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/Ri0nGo/gokit/set"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCuckooFilter(t *testing.T) {
+	testCase := []struct {
+		name            string
+		bucketSize      uint8
+		fingerprintBits uint8
+	}{
+		{name: "default", bucketSize: 0, fingerprintBits: 0},
+		{name: "bucket 2, 12 bits", bucketSize: 2, fingerprintBits: 12},
+		{name: "bucket 8, 20 bits", bucketSize: 8, fingerprintBits: 20},
+		{name: "bucket 1, 32 bits", bucketSize: 1, fingerprintBits: 32},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			const n = 10000
+			c := NewCuckooFilter[string](n, tc.bucketSize, tc.fingerprintBits)
+			for i := 0; i < n; i++ {
+				assert.NoError(t, c.Insert("key-"+strconv.Itoa(i)))
+			}
+			assert.Equal(t, uint64(n), c.Count())
+			// 没有漏判
+			for i := 0; i < n; i++ {
+				assert.True(t, c.Lookup("key-"+strconv.Itoa(i)))
+			}
+
+			fp := 0
+			const probes = 100000
+			for i := 0; i < probes; i++ {
+				if c.Lookup("other-" + strconv.Itoa(i)) {
+					fp++
+				}
+			}
+			rate := 2 * float64(c.BucketSize()) / float64(uint64(1)<<c.FingerprintBits())
+			assert.LessOrEqual(t, float64(fp)/probes, 2*rate+0.0005)
+
+			// 删除一半后剩余的仍然存在
+			for i := 0; i < n; i += 2 {
+				assert.True(t, c.Delete("key-"+strconv.Itoa(i)))
+			}
+			assert.Equal(t, uint64(n/2), c.Count())
+			for i := 1; i < n; i += 2 {
+				assert.True(t, c.Lookup("key-"+strconv.Itoa(i)))
+			}
+		})
+	}
+}
+
+func TestCuckooFilterFull(t *testing.T) {
+	c := NewCuckooFilter[int](16, 4, 8)
+	var inserted []int
+	for i := 0; ; i++ {
+		if err := c.Insert(i); err != nil {
+			assert.ErrorIs(t, err, ErrFilterFull)
+			break
+		}
+		inserted = append(inserted, i)
+	}
+	assert.Equal(t, uint64(len(inserted)), c.Count())
+	assert.LessOrEqual(t, c.Count(), c.Cap())
+	// 插入失败不会丢失已有的数据
+	for _, k := range inserted {
+		assert.True(t, c.Lookup(k))
+	}
+
+	c.Clear()
+	assert.Equal(t, uint64(0), c.Count())
+	assert.False(t, c.Lookup(inserted[0]))
+}
+
+func TestCuckooFilterDuplicate(t *testing.T) {
+	c := NewCuckooFilter[string](100, 0, 0)
+	assert.NoError(t, c.Insert("a"))
+	assert.NoError(t, c.Insert("a"))
+	assert.True(t, c.Delete("a"))
+	assert.True(t, c.Lookup("a"))
+	assert.True(t, c.Delete("a"))
+	assert.False(t, c.Lookup("a"))
+	assert.False(t, c.Delete("a"))
+}
+
+func TestCuckooFilterBinary(t *testing.T) {
+	c := NewCuckooFilter[int](1000, 4, 12)
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, c.Insert(i))
+	}
+	data, err := c.MarshalBinary()
+	assert.NoError(t, err)
+
+	var got CuckooFilter[int]
+	assert.NoError(t, got.UnmarshalBinary(data))
+	assert.Equal(t, c.Count(), got.Count())
+	assert.Equal(t, c.Cap(), got.Cap())
+	for i := 0; i < 1000; i++ {
+		assert.True(t, got.Lookup(i))
+	}
+	assert.True(t, got.Delete(0))
+
+	assert.Error(t, got.UnmarshalBinary(nil))
+	assert.Error(t, got.UnmarshalBinary(data[:len(data)-1]))
+	bad := append([]byte(nil), data...)
+	bad[3] = 40
+	assert.Error(t, got.UnmarshalBinary(bad))
+}
+
+// FuzzCuckooFilter 按 data 执行插入与删除，并与 set.Set 比对：
+// 不能漏判，删除已插入的 key 必须成功，插入失败不影响已有数据
+func FuzzCuckooFilter(f *testing.F) {
+	f.Add(uint8(4), uint8(8), []byte{0, 1, 0, 2, 1, 1, 0, 1})
+	f.Add(uint8(1), uint8(4), []byte{0, 1, 0, 2, 0, 3, 0, 4, 0, 5, 1, 3})
+	f.Add(uint8(2), uint8(31), []byte{0, 255, 0, 254, 1, 255, 0, 255})
+
+	f.Fuzz(func(t *testing.T, bucketSize, fpBits uint8, data []byte) {
+		// 过滤器满后每次插入都要搬迁 maxKicks 次，限制操作数避免单次执行过慢
+		if len(data) > 3000 {
+			data = data[:3000]
+		}
+		c := NewCuckooFilter[uint16](32, bucketSize%maxBucketSize+1, fpBits%32+1)
+		model := set.NewSet[uint16]()
+		for i := 0; i+2 < len(data); i += 3 {
+			key := uint16(data[i+1]) | uint16(data[i+2])<<8
+			switch {
+			case data[i]%2 == 0 && !model.Contains(key):
+				if err := c.Insert(key); err == nil {
+					model.Add(key)
+				} else if err != ErrFilterFull {
+					t.Fatalf("insert %d: %v", key, err)
+				}
+			case data[i]%2 == 1 && model.Contains(key):
+				if !c.Delete(key) {
+					t.Fatalf("delete %d: not found", key)
+				}
+				model.Delete(key)
+			}
+			if c.Count() != uint64(model.Len()) {
+				t.Fatalf("count %d != %d", c.Count(), model.Len())
+			}
+		}
+		for k := range model.All() {
+			if !c.Lookup(k) {
+				t.Fatalf("false negative %d", k)
+			}
+		}
+
+		encoded, _ := c.MarshalBinary()
+		var decoded CuckooFilter[uint16]
+		if err := decoded.UnmarshalBinary(encoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.Count() != c.Count() {
+			t.Fatalf("decoded count %d != %d", decoded.Count(), c.Count())
+		}
+		for k := range model.All() {
+			if !decoded.Lookup(k) {
+				t.Fatalf("decoded false negative %d", k)
+			}
+		}
+	})
+}
+
+func BenchmarkCuckooFilterInsert(b *testing.B) {
+	c := NewCuckooFilter[int](uint64(b.N), 0, 0)
+	for i := 0; i < b.N; i++ {
+		_ = c.Insert(i)
+	}
+}
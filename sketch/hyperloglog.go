@@ -0,0 +1,220 @@
+package sketch
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"slices"
+
+	"github.com/Ri0nGo/gokit/internal/hashing"
+)
+
+const (
+	hllMagic        = 'H'
+	minPrecision    = 4
+	maxPrecision    = 18
+	defaultHLLPrecs = 14
+	// sparsePrecision 稀疏表示使用更高的精度，基数较小时几乎没有误差
+	sparsePrecision = 25
+)
+
+// HyperLogLog 基数（不重复元素个数）估计，标准误差约为 1.04/sqrt(2^precision)。
+// 元素较少时使用稀疏表示，只记录出现过的寄存器，元素增多后自动转换为 2^precision 个字节的稠密表示
+type HyperLogLog[K comparable] struct {
+	p uint8
+	// sparse 不为 nil 时为稀疏表示，key 为 sparsePrecision 精度下的寄存器下标
+	sparse map[uint32]uint8
+	dense  []uint8
+	hash   func(K) uint64
+}
+
+// NewHyperLogLog 创建 HyperLogLog，precision 取值范围为 [4, 18]，超出范围时取最近的边界值，0 表示默认值 14
+func NewHyperLogLog[K comparable](precision uint8, opts ...Option) *HyperLogLog[K] {
+	if precision == 0 {
+		precision = defaultHLLPrecs
+	}
+	return &HyperLogLog[K]{
+		p:      min(max(precision, minPrecision), maxPrecision),
+		sparse: make(map[uint32]uint8),
+		hash:   hasherFunc[K](opts),
+	}
+}
+
+// Add 添加元素
+func (h *HyperLogLog[K]) Add(key K) {
+	// 再混合一次，保证字符串的 FNV 哈希高位也足够随机
+	x := hashing.Mix64(h.hash(key))
+	if h.sparse != nil {
+		idx, rho := register(x, sparsePrecision)
+		if rho > h.sparse[idx] {
+			h.sparse[idx] = rho
+			if len(h.sparse) > h.sparseLimit() {
+				h.toDense()
+			}
+		}
+		return
+	}
+	idx, rho := register(x, h.p)
+	h.dense[idx] = max(h.dense[idx], rho)
+}
+
+// Count 返回估计的基数
+func (h *HyperLogLog[K]) Count() uint64 {
+	if h.sparse != nil {
+		// 稀疏表示下使用线性计数
+		m := float64(uint64(1) << sparsePrecision)
+		return uint64(math.Round(m * math.Log(m/(m-float64(len(h.sparse))))))
+	}
+
+	m := float64(len(h.dense))
+	sum, zeros := 0.0, 0
+	for _, r := range h.dense {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	est := alpha(len(h.dense)) * m * m / sum
+	if est <= 2.5*m && zeros > 0 {
+		est = m * math.Log(m/float64(zeros))
+	}
+	return uint64(math.Round(est))
+}
+
+// Precision 返回精度
+func (h *HyperLogLog[K]) Precision() uint8 {
+	return h.p
+}
+
+// Clear 清空并恢复为稀疏表示
+func (h *HyperLogLog[K]) Clear() {
+	h.sparse = make(map[uint32]uint8)
+	h.dense = nil
+}
+
+// Merge 合并另一个精度相同的 HyperLogLog，合并后的基数为两者的并集
+func (h *HyperLogLog[K]) Merge(other *HyperLogLog[K]) error {
+	if h.p != other.p {
+		return ErrIncompatible
+	}
+	if h.sparse != nil && other.sparse != nil {
+		for idx, rho := range other.sparse {
+			h.sparse[idx] = max(h.sparse[idx], rho)
+		}
+		if len(h.sparse) > h.sparseLimit() {
+			h.toDense()
+		}
+		return nil
+	}
+	if h.sparse != nil {
+		h.toDense()
+	}
+	if other.sparse != nil {
+		for idx, rho := range other.sparse {
+			i, r := h.denseRegister(idx, rho)
+			h.dense[i] = max(h.dense[i], r)
+		}
+		return nil
+	}
+	for i, r := range other.dense {
+		h.dense[i] = max(h.dense[i], r)
+	}
+	return nil
+}
+
+// MarshalBinary 编码为二进制：magic、version、precision、是否稀疏，
+// 稀疏表示之后为按下标排序的 (下标<<8 | rho) 列表，稠密表示之后为所有寄存器
+func (h *HyperLogLog[K]) MarshalBinary() ([]byte, error) {
+	data := []byte{hllMagic, binaryVersion, h.p}
+	if h.sparse == nil {
+		data = append(data, 0)
+		return append(data, h.dense...), nil
+	}
+	data = append(data, 1)
+	entries := make([]uint32, 0, len(h.sparse))
+	for idx, rho := range h.sparse {
+		entries = append(entries, idx<<8|uint32(rho))
+	}
+	slices.Sort(entries)
+	for _, e := range entries {
+		data = binary.LittleEndian.AppendUint32(data, e)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary 解码 MarshalBinary 的输出，可以解码到零值的 HyperLogLog 中（使用默认哈希函数）
+func (h *HyperLogLog[K]) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 || data[0] != hllMagic || data[1] != binaryVersion ||
+		data[2] < minPrecision || data[2] > maxPrecision || data[3] > 1 {
+		return errInvalidData
+	}
+	p, body := data[2], data[4:]
+	if data[3] == 0 {
+		if len(body) != 1<<p || slices.Max(body) > 64-p+1 {
+			return errInvalidData
+		}
+		h.p, h.sparse, h.dense = p, nil, slices.Clone(body)
+	} else {
+		if len(body)%4 != 0 {
+			return errInvalidData
+		}
+		sparse := make(map[uint32]uint8, len(body)/4)
+		for i := 0; i < len(body); i += 4 {
+			e := binary.LittleEndian.Uint32(body[i:])
+			if e>>8 >= 1<<sparsePrecision || uint8(e) > 64-sparsePrecision+1 {
+				return errInvalidData
+			}
+			sparse[e>>8] = uint8(e)
+		}
+		h.p, h.sparse, h.dense = p, sparse, nil
+	}
+	if h.hash == nil {
+		h.hash = hasherFunc[K](nil)
+	}
+	return nil
+}
+
+// sparseLimit 稀疏表示的每个条目约占 16 字节，超过稠密表示的大小时转换
+func (h *HyperLogLog[K]) sparseLimit() int {
+	return (1 << h.p) / 16
+}
+
+func (h *HyperLogLog[K]) toDense() {
+	h.dense = make([]uint8, 1<<h.p)
+	for idx, rho := range h.sparse {
+		i, r := h.denseRegister(idx, rho)
+		h.dense[i] = max(h.dense[i], r)
+	}
+	h.sparse = nil
+}
+
+// denseRegister 将 sparsePrecision 精度下的寄存器换算为 p 精度下的寄存器
+func (h *HyperLogLog[K]) denseRegister(idx uint32, rho uint8) (uint32, uint8) {
+	shift := sparsePrecision - uint32(h.p)
+	low := idx & (1<<shift - 1)
+	if low != 0 {
+		// 多出的下标位中第一个 1 决定了 p 精度下的 rho
+		return idx >> shift, uint8(shift) - uint8(bits.Len32(low)) + 1
+	}
+	return idx >> shift, rho + uint8(shift)
+}
+
+// register 高 p 位为寄存器下标，其余位中前导 0 的个数 + 1 为 rho
+func register(x uint64, p uint8) (uint32, uint8) {
+	idx := uint32(x >> (64 - p))
+	w := x<<p | 1<<(p-1)
+	return idx, uint8(bits.LeadingZeros64(w)) + 1
+}
+
+func alpha(m int) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/float64(m))
+	}
+}
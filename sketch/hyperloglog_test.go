@@ -0,0 +1,111 @@
+package sketch
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	testCase := []struct {
+		name      string
+		precision uint8
+		n         int
+	}{
+		{name: "empty", precision: 14, n: 0},
+		{name: "sparse", precision: 14, n: 500},
+		{name: "dense", precision: 14, n: 100000},
+		{name: "low precision", precision: 8, n: 50000},
+		{name: "default precision", precision: 0, n: 20000},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewHyperLogLog[string](tc.precision)
+			for i := 0; i < tc.n; i++ {
+				h.Add("key-" + strconv.Itoa(i))
+				// 重复添加不影响结果
+				h.Add("key-" + strconv.Itoa(i))
+			}
+			m := float64(uint64(1) << h.Precision())
+			delta := 3 * 1.04 / math.Sqrt(m) * float64(tc.n)
+			assert.InDelta(t, float64(tc.n), float64(h.Count()), delta+1)
+		})
+	}
+}
+
+func TestHyperLogLogPrecision(t *testing.T) {
+	assert.Equal(t, uint8(14), NewHyperLogLog[int](0).Precision())
+	assert.Equal(t, uint8(4), NewHyperLogLog[int](1).Precision())
+	assert.Equal(t, uint8(18), NewHyperLogLog[int](30).Precision())
+}
+
+func TestHyperLogLogMerge(t *testing.T) {
+	testCase := []struct {
+		name string
+		a, b int
+	}{
+		{name: "sparse+sparse", a: 100, b: 100},
+		{name: "sparse+dense", a: 100, b: 50000},
+		{name: "dense+sparse", a: 50000, b: 100},
+		{name: "dense+dense", a: 50000, b: 50000},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b := NewHyperLogLog[int](12), NewHyperLogLog[int](12)
+			for i := 0; i < tc.a; i++ {
+				a.Add(i)
+			}
+			// b 与 a 有一半重叠
+			start := tc.a / 2
+			for i := start; i < start+tc.b; i++ {
+				b.Add(i)
+			}
+			assert.NoError(t, a.Merge(b))
+			want := float64(start + tc.b)
+			if tc.a > start+tc.b {
+				want = float64(tc.a)
+			}
+			assert.InDelta(t, want, float64(a.Count()), 3*1.04/64*want+1)
+		})
+	}
+
+	assert.ErrorIs(t, NewHyperLogLog[int](12).Merge(NewHyperLogLog[int](10)), ErrIncompatible)
+}
+
+func TestHyperLogLogBinary(t *testing.T) {
+	for _, n := range []int{0, 100, 100000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			h := NewHyperLogLog[int](12)
+			for i := 0; i < n; i++ {
+				h.Add(i)
+			}
+			data, err := h.MarshalBinary()
+			assert.NoError(t, err)
+
+			var got HyperLogLog[int]
+			assert.NoError(t, got.UnmarshalBinary(data))
+			assert.Equal(t, h.Count(), got.Count())
+			assert.Equal(t, h.Precision(), got.Precision())
+
+			// 解码后可以继续使用
+			got.Add(n + 1)
+			h.Add(n + 1)
+			assert.Equal(t, h.Count(), got.Count())
+		})
+	}
+
+	var h HyperLogLog[int]
+	assert.Error(t, h.UnmarshalBinary(nil))
+	assert.Error(t, h.UnmarshalBinary([]byte{'B', binaryVersion, 12, 0}))
+}
+
+func BenchmarkHyperLogLogAdd(b *testing.B) {
+	h := NewHyperLogLog[int](14)
+	for i := 0; i < b.N; i++ {
+		h.Add(i)
+	}
+}
@@ -0,0 +1,55 @@
+// Package sketch 提供概率数据结构，以较小的内存换取近似的结果。
+// 默认使用固定种子的 hashing.StableHash（即原 ShardMap 的 fastHash），字符串与整数的哈希值跨进程稳定，
+// 因此以它们为 key 的结构可以序列化后在其他进程中使用；其他类型的 key 需要通过 WithHasher 指定稳定的哈希函数。
+// 不是并发安全的！！！
+package sketch
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Ri0nGo/gokit/internal/hashing"
+)
+
+var (
+	// ErrIncompatible 合并参数（大小、哈希函数个数等）不同的结构
+	ErrIncompatible = errors.New("sketch: incompatible parameters")
+	errInvalidData  = errors.New("sketch: invalid binary data")
+)
+
+// Option 用于配置 sketch 中的结构
+type Option func(*options)
+
+type options struct {
+	hasher any // func(K) uint64
+}
+
+// WithHasher 自定义 key 的哈希函数，需返回分布均匀的 64 位哈希值
+func WithHasher[K comparable](hasher func(K) uint64) Option {
+	return func(o *options) {
+		o.hasher = hasher
+	}
+}
+
+// hasherFunc 取出哈希函数，未设置时使用 hashing.StableHash，类型与 K 不匹配时 panic
+func hasherFunc[K comparable](opts []Option) func(K) uint64 {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.hasher == nil {
+		return hashing.StableHash[K]
+	}
+	fn, ok := o.hasher.(func(K) uint64)
+	if !ok {
+		var k K
+		panic(fmt.Sprintf("sketch: WithHasher expects func(%T) uint64, got %T", k, o.hasher))
+	}
+	return fn
+}
+
+// doubleHash 由一个 64 位哈希值派生出两个哈希值，第 i 个位置为 h1 + i*h2（Kirsch-Mitzenmacher）。
+// h2 为奇数，保证在 2 的幂大小的表中也能遍历到不同位置
+func doubleHash(h uint64) (h1, h2 uint64) {
+	return h, hashing.Mix64(h^0x9e3779b97f4a7c15) | 1
+}
@@ -56,6 +56,7 @@ func Contains[T comparable](silce []T, ele T) bool {
 // SetSlice[T comparable] 去除slice中重复的元素，并保持原来slice的稳定性
 // 例如：[1,2,4,2,5]
 // 结果：[1,2,4,5]
+// 需要保留每个元素出现的次数时可以使用 set.MultiSetFromSlice
 func SetSlice[T comparable](slice []T) []T {
 	var (
 		result []T
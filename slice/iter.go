@@ -0,0 +1,35 @@
+package slice
+
+import "iter"
+
+// Collect[T any] 将迭代器中的元素收集到切片中
+// 例如：slice.Collect(m.KeysSeq())
+func Collect[T any](seq iter.Seq[T]) []T {
+	var result []T
+	for v := range seq {
+		result = append(result, v)
+	}
+	return result
+}
+
+// Values[T any] 返回按顺序遍历切片元素的迭代器
+func Values[T any](slice []T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range slice {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeq[T any] 返回只包含执行func后结果为true的元素的迭代器
+func FilterSeq[T any](seq iter.Seq[T], filterFunc filterFunc[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if filterFunc(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package slice
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCollect(t *testing.T) {
+	testCase := []struct {
+		name  string
+		slice []int
+		want  []int
+	}{
+		{
+			name:  "normal slice",
+			slice: []int{1, 2, 3},
+			want:  []int{1, 2, 3},
+		},
+		{
+			name:  "empty slice",
+			slice: []int{},
+			want:  []int(nil),
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Collect(Values(tc.slice))
+			assert.Equal(t, tc.want, result)
+		})
+	}
+}
+
+func TestValuesStop(t *testing.T) {
+	var result []int
+	for v := range Values([]int{1, 2, 3, 4}) {
+		if v == 3 {
+			break
+		}
+		result = append(result, v)
+	}
+	assert.Equal(t, []int{1, 2}, result)
+}
+
+func TestFilterSeq(t *testing.T) {
+	testCase := []struct {
+		name  string
+		slice []int
+		match filterFunc[int]
+		want  []int
+	}{
+		{
+			name:  "even",
+			slice: []int{1, 2, 3, 4},
+			match: func(elem int) bool {
+				return elem%2 == 0
+			},
+			want: []int{2, 4},
+		},
+		{
+			name:  "none",
+			slice: []int{1, 2, 3, 4},
+			match: func(elem int) bool {
+				return elem > 4
+			},
+			want: []int(nil),
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Collect(FilterSeq(Values(tc.slice), tc.match))
+			assert.Equal(t, tc.want, result)
+		})
+	}
+}